@@ -1,5 +1,124 @@
 package api
 
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/kaecloud/kaectl/pkg/spec"
+)
+
 type AppClient struct {
+	Client
+}
+
+type App struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Git       string    `json:"git"`
+	SpecText  string    `json:"spec_text"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Release is an immutable version of an app, deploying a release
+// deploys its image with its spec.
+type Release struct {
+	Id        int64     `json:"id"`
+	Tag       string    `json:"tag"`
+	Image     string    `json:"image"`
+	SpecText  string    `json:"spec_text"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewAppClient(httpClient *http.Client, baseUrl string, accessTok string) *AppClient {
+	c := NewClientFromHTTP(httpClient)
+	c.baseUrl = baseUrl
+	c.accessToken = accessTok
+	return &AppClient{
+		Client: *c,
+	}
+}
+
+func (c *AppClient) Get(ctx context.Context, name string) (*App, error) {
+	path := fmt.Sprintf("/api/v1/apps/%s", name)
+	var data App
+	err := c.REST(ctx, "GET", path, nil, &data)
+	return &data, err
+}
+
+func (c *AppClient) List(ctx context.Context) ([]*App, error) {
+	path := "/api/v1/apps"
+	var data []*App
+	err := c.REST(ctx, "GET", path, nil, &data)
+	return data, err
+}
+
+func (c *AppClient) Create(ctx context.Context, args *spec.CreateAppArgs) (*App, error) {
+	path := "/api/v1/apps"
+	reqBytes, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	res := App{}
+	err = c.REST(ctx, "POST", path, bytes.NewReader(reqBytes), &res)
+	return &res, err
+}
+
+// Deploy deploys the app to the cluster given in args, the spec registered
+// with the app is used when args doesn't contain one.
+func (c *AppClient) Deploy(ctx context.Context, name string, args *spec.DeployAppArgs) error {
+	path := fmt.Sprintf("/api/v1/apps/%s/deploy", name)
+	reqBytes, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	var res App
+	return c.REST(ctx, "POST", path, bytes.NewReader(reqBytes), &res)
+}
+
+// Releases returns the releases of the app, newest first.
+func (c *AppClient) Releases(ctx context.Context, name string) ([]*Release, error) {
+	path := fmt.Sprintf("/api/v1/apps/%s/releases", name)
+	var data []*Release
+	err := c.REST(ctx, "GET", path, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].CreatedAt.After(data[j].CreatedAt)
+	})
+	return data, nil
+}
+
+// Deployment is the release of an app deployed in a cluster
+type Deployment struct {
+	Cluster string `json:"cluster"`
+	// Tag is the tag of the deployed release, it is empty when the app is
+	// not deployed in the cluster
+	Tag        string    `json:"tag"`
+	Image      string    `json:"image"`
+	DeployedAt time.Time `json:"deployed_at"`
+}
+
+// Deployment returns the release of the app deployed in cluster, as reported
+// by the server from the running workload rather than the release records.
+func (c *AppClient) Deployment(ctx context.Context, name, cluster string) (*Deployment, error) {
+	path := fmt.Sprintf("/api/v1/apps/%s/deployment?%s", url.PathEscape(name), url.Values{"cluster": {cluster}}.Encode())
+	var data Deployment
+	err := c.REST(ctx, "GET", path, nil, &data)
+	return &data, err
+}
 
+func (c *AppClient) Delete(ctx context.Context, name string) error {
+	path := fmt.Sprintf("/api/v1/apps/%s", name)
+	var data App
+	return c.REST(ctx, "DELETE", path, nil, &data)
 }
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppClient_Deployment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/apps/my-app/deployment" || r.URL.Query().Get("cluster") != "mycluster" {
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"cluster": "mycluster", "tag": "v2", "image": "registry.example.com/my-app:v2"}`))
+	}))
+	defer srv.Close()
+
+	c := NewAppClient(NewHTTPClient(), srv.URL, "TOKEN")
+	d, err := c.Deployment(context.Background(), "my-app", "mycluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Tag != "v2" || d.Cluster != "mycluster" {
+		t.Errorf("Deployment = %+v", d)
+	}
+}
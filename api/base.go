@@ -1,13 +1,25 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/utils"
 )
 
 // HTTPError is an error returned by a failed API call
@@ -33,6 +45,305 @@ type Client struct {
 // ClientOption represents an argument to NewClient
 type ClientOption = func(http.RoundTripper) http.RoundTripper
 
+// ReplaceTripper substitutes the underlying RoundTripper with a custom one
+func ReplaceTripper(tr http.RoundTripper) ClientOption {
+	return func(http.RoundTripper) http.RoundTripper {
+		return tr
+	}
+}
+
+// TransportOptions configures the connections made by NewTransport
+type TransportOptions struct {
+	// Timeout limits the time spent on connecting to the server and waiting
+	// for the response headers, 0 means no timeout
+	Timeout time.Duration
+	// CAFile is a PEM file of the CA certificates trusted besides the
+	// system ones
+	CAFile string
+	// ClientCert and ClientKey are the PEM files of the certificate
+	// presented to the server and its private key
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables verifying the certificate of the server
+	InsecureSkipVerify bool
+	// ProxyURL is the proxy of all requests, the proxy is chosen by the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables when it
+	// is empty
+	ProxyURL string
+}
+
+// TransportOptionsFromConfig returns the TransportOptions set in cfg
+func TransportOptionsFromConfig(cfg *config.CmdConfig) (TransportOptions, error) {
+	opts := TransportOptions{
+		CAFile:     cfg.CAFile,
+		ClientCert: cfg.ClientCert,
+		ClientKey:  cfg.ClientKey,
+		ProxyURL:   cfg.ProxyURL,
+	}
+	if cfg.InsecureSkipVerify != "" {
+		insecure, err := strconv.ParseBool(string(cfg.InsecureSkipVerify))
+		if err != nil {
+			return opts, fmt.Errorf("invalid value %q of insecure_skip_verify: must be true or false", cfg.InsecureSkipVerify)
+		}
+		opts.InsecureSkipVerify = insecure
+	}
+	return opts, nil
+}
+
+// NewTransport returns a copy of http.DefaultTransport configured by opts.
+// Unlike http.Client.Timeout, opts.Timeout doesn't limit the time spent on
+// uploading or downloading a large body.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Timeout > 0 {
+		tr.DialContext = (&net.Dialer{
+			Timeout:   opts.Timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		tr.TLSHandshakeTimeout = opts.Timeout
+		tr.ResponseHeaderTimeout = opts.Timeout
+	}
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.ProxyURL)
+		}
+		tr.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	tr.TLSClientConfig = tlsConfig
+	return tr, nil
+}
+
+func newTLSConfig(opts TransportOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CAFile != "" {
+		pem, err := ioutil.ReadFile(utils.ExpandUser(opts.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in ca_file %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		if opts.ClientCert == "" || opts.ClientKey == "" {
+			return nil, errors.New("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(utils.ExpandUser(opts.ClientCert), utils.ExpandUser(opts.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// RetryPolicy configures the RoundTripper of WithRetry
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt,
+	// 0 disables retrying
+	MaxRetries int
+	// MinDelay is the delay before the first retry, it doubles on every
+	// retry and a random jitter of up to half of it is subtracted
+	MinDelay time.Duration
+	// MaxDelay caps the delay between two attempts, a request isn't retried
+	// when the server asks to wait longer with Retry-After
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used by kaectl unless --retries is given
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinDelay:   500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// WithRetry retries idempotent requests which failed with a transient network
+// error or a 429, 502, 503 or 504 response. GET, HEAD, OPTIONS, PUT and DELETE
+// requests are idempotent, other requests only when their context is marked
+// with WithIdempotent and their body can be replayed.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(tr http.RoundTripper) http.RoundTripper {
+		return &retryTripper{policy: policy, tr: tr}
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks the requests made with ctx as safe to retry
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+type retryTripper struct {
+	policy RetryPolicy
+	tr     http.RoundTripper
+}
+
+func (rt *retryTripper) Unwrap() http.RoundTripper {
+	return rt.tr
+}
+
+func (rt *retryTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return rt.tr.RoundTrip(req)
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		resp, err := rt.tr.RoundTrip(r)
+		if attempt >= rt.policy.MaxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := rt.policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				if after > rt.policy.MaxDelay {
+					return resp, err
+				}
+				if after > delay {
+					delay = after
+				}
+			}
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinDelay
+	for i := 0; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		if req.Body == nil || req.Body == http.NoBody {
+			return true
+		}
+	default:
+		if marked, _ := req.Context().Value(idempotentKey{}).(bool); !marked {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTransientNetError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientNetError reports whether a request failed with err may succeed
+// when retried. Certificate and TLS errors and unknown hosts fail again, as
+// do the errors of invalid requests which aren't network errors.
+func isTransientNetError(err error) bool {
+	switch {
+	case errors.As(err, &x509.UnknownAuthorityError{}),
+		errors.As(err, &x509.HostnameError{}),
+		errors.As(err, &x509.CertificateInvalidError{}),
+		errors.As(err, &tls.RecordHeaderError{}):
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	// the server may close an idle connection while the request is sent
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses the Retry-After header of resp, which is either a number
+// of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// baseTransport returns the *http.Transport wrapped by the RoundTrippers of
+// ClientOption, wrappers expose the RoundTripper they wrap with Unwrap.
+func baseTransport(rt http.RoundTripper) *http.Transport {
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t
+		case interface{ Unwrap() http.RoundTripper }:
+			rt = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
 // NewHTTPClient initializes an http.Client
 func NewHTTPClient(opts ...ClientOption) *http.Client {
 	tr := http.DefaultTransport
@@ -54,6 +365,35 @@ func NewClientFromHTTP(httpClient *http.Client) *Client {
 	return client
 }
 
+// websocketDialer returns a websocket dialer which connects to the server
+// the same way as the http client.
+func (c Client) websocketDialer() *websocket.Dialer {
+	d := *websocket.DefaultDialer
+	if tr := baseTransport(c.http.Transport); tr != nil {
+		d.Proxy = tr.Proxy
+		d.TLSClientConfig = tr.TLSClientConfig
+		if tr.ResponseHeaderTimeout > 0 {
+			d.HandshakeTimeout = tr.ResponseHeaderTimeout
+		}
+	}
+	return &d
+}
+
+// dialWebsocket opens a websocket connection, the handshake is logged like
+// other requests when verbose logging is enabled.
+func (c Client) dialWebsocket(ctx context.Context, u string, header http.Header) (*websocket.Conn, error) {
+	start := time.Now()
+	ws, resp, err := c.websocketDialer().DialContext(ctx, u, header)
+	if logger := findVerboseLogger(c.http.Transport); logger != nil {
+		logger.logHandshake(u, header, resp, err, time.Since(start))
+	}
+	// the server rejected the request, e.g. the job doesn't exist
+	if err == websocket.ErrBadHandshake && resp != nil && resp.StatusCode >= 400 {
+		return nil, handleHTTPError(resp)
+	}
+	return ws, err
+}
+
 func (c Client) FullUrl(p string) string {
 	url := strings.TrimRight(c.baseUrl, " /") + p
 	return url
@@ -70,9 +410,9 @@ func (c Client) FullWebsocketUrl(p string) string {
 	}
 }
 // REST performs a REST request and parses the response.
-func (c Client) REST(method string, p string, body io.Reader, data interface{}) error {
+func (c Client) REST(ctx context.Context, method string, p string, body io.Reader, data interface{}) error {
 	url := strings.TrimRight(c.baseUrl, " /") + p
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return err
 	}
@@ -125,12 +465,5 @@ func handleHTTPError(resp *http.Response) error {
 		return httpError
 	}
 
-	var parsedBody struct {
-		Message string `json:"message"`
-	}
-	if err := json.Unmarshal(body, &parsedBody); err == nil {
-		httpError.Message = parsedBody.Message
-	}
-
-	return httpError
+	return newAPIError(httpError, body)
 }
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kaecloud/kaectl/pkg/spec"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 2,
+	MinDelay:   time.Millisecond,
+	MaxDelay:   10 * time.Millisecond,
+}
+
+// newFlakyServer returns a server which replies the given status codes in
+// order and 200 afterwards, a status of 0 drops the connection.
+func newFlakyServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&count, 1))
+		if n > len(statuses) {
+			w.Write([]byte(`{"name": "myjob", "data": "obj-url"}`))
+			return
+		}
+		switch statuses[n-1] {
+		case 0:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			conn.Close()
+		case http.StatusTooManyRequests:
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(statuses[n-1])
+		}
+	}))
+	return srv, &count
+}
+
+func newTestJobClient(srv *httptest.Server) *JobClient {
+	httpClient := NewHTTPClient(WithRetry(testRetryPolicy))
+	return NewJobClient(httpClient, srv.URL, "TOKEN")
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		do        func(c *JobClient) error
+		wantCalls int32
+		wantCode  int
+	}{
+		{
+			name:      "GET succeeds after retries",
+			statuses:  []int{503, 0},
+			do:        func(c *JobClient) error { _, err := c.Get(context.Background(), "myjob"); return err },
+			wantCalls: 3,
+		},
+		{
+			name:      "GET gives up after MaxRetries",
+			statuses:  []int{502, 504, 503, 503},
+			do:        func(c *JobClient) error { _, err := c.Get(context.Background(), "myjob"); return err },
+			wantCalls: 3,
+			wantCode:  503,
+		},
+		{
+			name:      "DELETE is retried",
+			statuses:  []int{502},
+			do:        func(c *JobClient) error { return c.Delete(context.Background(), "myjob") },
+			wantCalls: 2,
+		},
+		{
+			name:     "POST is not retried",
+			statuses: []int{503},
+			do: func(c *JobClient) error {
+				_, err := c.Create(context.Background(), &spec.CreateJobArgs{Name: "myjob"})
+				return err
+			},
+			wantCalls: 1,
+			wantCode:  503,
+		},
+		{
+			name:      "client errors are not retried",
+			statuses:  []int{404},
+			do:        func(c *JobClient) error { _, err := c.Get(context.Background(), "myjob"); return err },
+			wantCalls: 1,
+			wantCode:  404,
+		},
+		{
+			name:      "Retry-After longer than MaxDelay",
+			statuses:  []int{429},
+			do:        func(c *JobClient) error { _, err := c.Get(context.Background(), "myjob"); return err },
+			wantCalls: 1,
+			wantCode:  429,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, count := newFlakyServer(t, tt.statuses...)
+			defer srv.Close()
+			err := tt.do(newTestJobClient(srv))
+
+			var httpErr HTTPError
+			if tt.wantCode == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.wantCode != 0 && (!errors.As(err, &httpErr) || httpErr.StatusCode != tt.wantCode) {
+				t.Errorf("error = %v, want status %d", err, tt.wantCode)
+			}
+			if got := atomic.LoadInt32(count); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithRetry_upload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaectl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "data.zip")
+	if err := ioutil.WriteFile(filename, []byte("artifact"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("fileUploadName")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if data, _ := ioutil.ReadAll(f); string(data) != "artifact" {
+			t.Errorf("uploaded %q, want %q", data, "artifact")
+		}
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"data": "obj-url"}`))
+	}))
+	defer srv.Close()
+
+	objUrl, err := newTestJobClient(srv).UploadArtifact(context.Background(), "myjob", filename, "data.zip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if objUrl != "obj-url" {
+		t.Errorf("objUrl = %q, want %q", objUrl, "obj-url")
+	}
+	if count != 2 {
+		t.Errorf("server called %d times, want 2", count)
+	}
+}
+
+func TestWithRetry_canceled(t *testing.T) {
+	srv, count := newFlakyServer(t, 503, 503, 503)
+	defer srv.Close()
+	httpClient := NewHTTPClient(WithRetry(RetryPolicy{
+		MaxRetries: 3,
+		MinDelay:   time.Hour,
+		MaxDelay:   time.Hour,
+	}))
+	c := NewJobClient(httpClient, srv.URL, "TOKEN")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Get(ctx, "myjob")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("server called %d times, want 1", got)
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{header: "", wantOK: false},
+		{header: "120", want: 2 * time.Minute, wantOK: true},
+		{header: "-1", wantOK: false},
+		{header: "Thu, 01 Oct 2020 12:00:30 GMT", want: 30 * time.Second, wantOK: true},
+		{header: "Thu, 01 Oct 2020 11:00:00 GMT", want: 0, wantOK: true},
+		{header: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		got, ok := retryAfter(resp, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewTransport_caFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "myjob"}`))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "kaectl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TransportOptions
+		wantErr bool
+	}{
+		{name: "untrusted", opts: TransportOptions{}, wantErr: true},
+		{name: "ca_file", opts: TransportOptions{CAFile: caFile}},
+		{name: "insecure_skip_verify", opts: TransportOptions{InsecureSkipVerify: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := NewTransport(tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			c := NewJobClient(NewHTTPClient(ReplaceTripper(tr)), srv.URL, "TOKEN")
+			_, err = c.Get(context.Background(), "myjob")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if d := c.websocketDialer(); d.TLSClientConfig != tr.TLSClientConfig {
+				t.Errorf("websocket dialer doesn't use the TLS config of transport")
+			}
+		})
+	}
+}
+
+func TestNewTransport_proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"name": "myjob"}`))
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportOptions{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewJobClient(NewHTTPClient(ReplaceTripper(tr)), "http://kae.example.com", "TOKEN")
+	if _, err := c.Get(context.Background(), "myjob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "http://kae.example.com/api/v1/jobs/myjob"; proxied != want {
+		t.Errorf("proxy got %q, want %q", proxied, want)
+	}
+
+	if _, err := NewTransport(TransportOptions{ProxyURL: "proxy:3128"}); err == nil {
+		t.Errorf("expected an error for a proxy URL without scheme")
+	}
+}
+
+func TestWithRetry_untrustedCertificate(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to a server with an untrusted certificate")
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// the transport trusts only the system CAs, not the test certificate
+	_, err := newTestJobClient(srv).Get(context.Background(), "myjob")
+	if !errors.As(err, &x509.UnknownAuthorityError{}) {
+		t.Errorf("error = %v, want x509.UnknownAuthorityError", err)
+	}
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Errorf("connected %d times, want 1", got)
+	}
+}
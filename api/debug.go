@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvDebug is the environment variable enabling verbose output, HTTP
+// headers and bodies are only logged when it contains "api".
+const EnvDebug = "DEBUG"
+
+// maxLoggedBody is the number of bytes of a body written to the log
+const maxLoggedBody = 64 * 1024
+
+// redactedValue replaces secrets in the log
+const redactedValue = "[redacted]"
+
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+var secretFields = map[string]bool{
+	"password":          true,
+	"client_secret":     true,
+	"access_token":      true,
+	"refresh_token":     true,
+	"id_token":          true,
+	"token":             true,
+	"code":              true,
+	"code_verifier":     true,
+	"device_code":       true,
+	"sso_password":      true,
+	"sso_client_secret": true,
+}
+
+// VerboseLog logs the method, URL, status and duration of every request to
+// out, headers and bodies are logged as well when logTraffic is true.
+// Credentials are redacted.
+func VerboseLog(out io.Writer, logTraffic bool) ClientOption {
+	return func(tr http.RoundTripper) http.RoundTripper {
+		return &verboseTripper{
+			logger: &verboseLogger{out: out, logTraffic: logTraffic},
+			tr:     tr,
+		}
+	}
+}
+
+// VerboseLogFromEnv returns the VerboseLog ClientOption enabled by the DEBUG
+// environment variable, the option doesn't change anything when DEBUG is
+// not set.
+func VerboseLogFromEnv(out io.Writer) ClientOption {
+	debug := os.Getenv(EnvDebug)
+	if debug == "" {
+		return func(tr http.RoundTripper) http.RoundTripper {
+			return tr
+		}
+	}
+	return VerboseLog(out, strings.Contains(debug, "api"))
+}
+
+type verboseTripper struct {
+	logger *verboseLogger
+	tr     http.RoundTripper
+}
+
+func (vt *verboseTripper) Unwrap() http.RoundTripper {
+	return vt.tr
+}
+
+func (vt *verboseTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	req = vt.logger.logRequest(req)
+	resp, err := vt.tr.RoundTrip(req)
+	vt.logger.logResponse(resp, err, time.Since(start))
+	return resp, err
+}
+
+// findVerboseLogger returns the logger of VerboseLog wrapped in rt
+func findVerboseLogger(rt http.RoundTripper) *verboseLogger {
+	for {
+		switch t := rt.(type) {
+		case *verboseTripper:
+			return t.logger
+		case interface{ Unwrap() http.RoundTripper }:
+			rt = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+type verboseLogger struct {
+	mu         sync.Mutex
+	out        io.Writer
+	logTraffic bool
+}
+
+func (l *verboseLogger) write(b *bytes.Buffer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = b.WriteTo(l.out)
+}
+
+// logRequest logs req and returns the request to send. The body is read
+// from a copy given by GetBody, or else from a clone of req which is
+// returned, as a RoundTripper must not modify the request of its caller.
+func (l *verboseLogger) logRequest(req *http.Request) *http.Request {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "* Request at %s\n", time.Now().Format("2006-01-02 15:04:05.000"))
+	fmt.Fprintf(&b, "> %s %s\n", req.Method, redactURL(req.URL))
+	if l.logTraffic {
+		writeHeaders(&b, ">", req.Header)
+		if req.Body != nil && req.Body != http.NoBody {
+			if isTextual(req.Header.Get("Content-Type")) {
+				var data []byte
+				var body io.ReadCloser
+				if req.GetBody != nil {
+					body, _ = req.GetBody()
+				}
+				if body != nil {
+					data, _ = peekBody(body)
+				} else {
+					req = req.Clone(req.Context())
+					data, req.Body = peekBody(req.Body)
+				}
+				writeBody(&b, req.Header.Get("Content-Type"), data)
+			} else {
+				fmt.Fprintf(&b, "(%s body omitted)\n", req.Header.Get("Content-Type"))
+			}
+		}
+	}
+	l.write(&b)
+	return req
+}
+
+func (l *verboseLogger) logResponse(resp *http.Response, err error, elapsed time.Duration) {
+	var b bytes.Buffer
+	if err != nil {
+		fmt.Fprintf(&b, "* Request failed after %s: %v\n", elapsed.Round(time.Millisecond), err)
+		l.write(&b)
+		return
+	}
+	fmt.Fprintf(&b, "< %s %s\n", resp.Proto, resp.Status)
+	if l.logTraffic {
+		writeHeaders(&b, "<", resp.Header)
+		if isTextual(resp.Header.Get("Content-Type")) {
+			var data []byte
+			data, resp.Body = peekBody(resp.Body)
+			writeBody(&b, resp.Header.Get("Content-Type"), data)
+		}
+	}
+	fmt.Fprintf(&b, "* Request took %s\n", elapsed.Round(time.Millisecond))
+	l.write(&b)
+}
+
+// logHandshake logs a websocket handshake, resp is nil when the connection
+// to the server failed.
+func (l *verboseLogger) logHandshake(u string, header http.Header, resp *http.Response, err error, elapsed time.Duration) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "* Websocket handshake at %s\n", time.Now().Add(-elapsed).Format("2006-01-02 15:04:05.000"))
+	parsed, parseErr := url.Parse(u)
+	if parseErr == nil {
+		u = redactURL(parsed)
+	}
+	fmt.Fprintf(&b, "> GET %s\n", u)
+	if l.logTraffic {
+		writeHeaders(&b, ">", header)
+	}
+	if resp != nil {
+		fmt.Fprintf(&b, "< %s %s\n", resp.Proto, resp.Status)
+		if l.logTraffic {
+			writeHeaders(&b, "<", resp.Header)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(&b, "* Handshake failed after %s: %v\n", elapsed.Round(time.Millisecond), err)
+	} else {
+		fmt.Fprintf(&b, "* Handshake took %s\n", elapsed.Round(time.Millisecond))
+	}
+	l.write(&b)
+}
+
+func writeHeaders(b *bytes.Buffer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range header[name] {
+			if secretHeaders[http.CanonicalHeaderKey(name)] {
+				v = redactHeader(v)
+			}
+			fmt.Fprintf(b, "%s %s: %s\n", prefix, name, v)
+		}
+	}
+	fmt.Fprintln(b, prefix)
+}
+
+// redactHeader keeps the scheme of an Authorization header, e.g. "Bearer"
+func redactHeader(v string) string {
+	if i := strings.IndexByte(v, ' '); i > 0 {
+		return v[:i+1] + redactedValue
+	}
+	return redactedValue
+}
+
+func redactURL(u *url.URL) string {
+	if u.User == nil && u.RawQuery == "" {
+		return u.String()
+	}
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(redactedValue)
+	}
+	if q := redacted.Query(); len(q) > 0 {
+		redactValues(q)
+		redacted.RawQuery = q.Encode()
+	}
+	return redacted.String()
+}
+
+func redactValues(values url.Values) {
+	for key := range values {
+		if secretFields[strings.ToLower(key)] {
+			values[key] = []string{redactedValue}
+		}
+	}
+}
+
+// peekBody reads the body and returns its content along with a reader
+// which replays it.
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	data, err := ioutil.ReadAll(body)
+	body.Close()
+	replay := ioutil.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		replay = ioutil.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+	}
+	return data, replay
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func isTextual(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "json") ||
+		strings.HasPrefix(contentType, "text/") ||
+		strings.HasPrefix(contentType, "application/x-www-form-urlencoded")
+}
+
+func writeBody(b *bytes.Buffer, contentType string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	if strings.HasPrefix(strings.ToLower(contentType), "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(data)); err == nil {
+			redactValues(values)
+			data = []byte(values.Encode())
+		}
+	} else if strings.Contains(strings.ToLower(contentType), "json") {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			if redacted, err := json.MarshalIndent(redactJSON(v), "", "  "); err == nil {
+				data = redacted
+			}
+		}
+	}
+	if len(data) > maxLoggedBody {
+		fmt.Fprintf(b, "%s\n(%d bytes truncated)\n", data[:maxLoggedBody], len(data)-maxLoggedBody)
+		return
+	}
+	fmt.Fprintf(b, "%s\n", data)
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if secretFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = redactJSON(value)
+		}
+	}
+	return v
+}
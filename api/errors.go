@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NotFoundError is returned when the server replies 404
+type NotFoundError struct {
+	HTTPError
+}
+
+func (e NotFoundError) Unwrap() error {
+	return e.HTTPError
+}
+
+// ConflictError is returned when the server replies 409, e.g. when a job
+// with the same name already exists
+type ConflictError struct {
+	HTTPError
+}
+
+func (e ConflictError) Unwrap() error {
+	return e.HTTPError
+}
+
+// UnauthorizedError is returned when the server replies 401, the access
+// token is missing, invalid or expired
+type UnauthorizedError struct {
+	HTTPError
+}
+
+func (e UnauthorizedError) Unwrap() error {
+	return e.HTTPError
+}
+
+// ForbiddenError is returned when the server replies 403, the user is not
+// allowed to access the resource
+type ForbiddenError struct {
+	HTTPError
+}
+
+func (e ForbiddenError) Unwrap() error {
+	return e.HTTPError
+}
+
+// FieldError is the error of a single field of a rejected request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when the server rejects the request with 400
+// or 422, Fields holds the errors of individual fields if the server
+// reported them.
+type ValidationError struct {
+	HTTPError
+	Fields []FieldError
+}
+
+func (e ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.HTTPError.Error()
+	}
+	var b strings.Builder
+	b.WriteString(e.HTTPError.Error())
+	for _, f := range e.Fields {
+		if f.Field == "" {
+			fmt.Fprintf(&b, "\n  - %s", f.Message)
+		} else {
+			fmt.Fprintf(&b, "\n  - %s: %s", f.Field, f.Message)
+		}
+	}
+	return b.String()
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.HTTPError
+}
+
+// ServerError is returned when the server replies 5xx
+type ServerError struct {
+	HTTPError
+}
+
+func (e ServerError) Unwrap() error {
+	return e.HTTPError
+}
+
+// errorBody is the error response of the KAE servers, older servers use
+// "error" or "msg" instead of "message". "errors" is either a map from
+// field to message or a list of FieldError.
+type errorBody struct {
+	Message string          `json:"message"`
+	Error   string          `json:"error"`
+	Msg     string          `json:"msg"`
+	Errors  json.RawMessage `json:"errors"`
+}
+
+func (b *errorBody) message() string {
+	for _, m := range []string{b.Message, b.Error, b.Msg} {
+		if m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+func (b *errorBody) fields() []FieldError {
+	if len(b.Errors) == 0 {
+		return nil
+	}
+	var list []FieldError
+	if err := json.Unmarshal(b.Errors, &list); err == nil {
+		return list
+	}
+	var byField map[string]interface{}
+	if err := json.Unmarshal(b.Errors, &byField); err != nil {
+		return nil
+	}
+	fields := make([]FieldError, 0, len(byField))
+	for field, v := range byField {
+		switch v := v.(type) {
+		case string:
+			fields = append(fields, FieldError{Field: field, Message: v})
+		case []interface{}:
+			for _, m := range v {
+				fields = append(fields, FieldError{Field: field, Message: fmt.Sprint(m)})
+			}
+		default:
+			fields = append(fields, FieldError{Field: field, Message: fmt.Sprint(v)})
+		}
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields
+}
+
+// newAPIError converts httpError to the typed error of its status code,
+// body is the response body of the failed request.
+func newAPIError(httpError HTTPError, body []byte) error {
+	var parsed errorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		httpError.Message = parsed.message()
+	}
+
+	switch code := httpError.StatusCode; {
+	case code == http.StatusNotFound:
+		return NotFoundError{httpError}
+	case code == http.StatusConflict:
+		return ConflictError{httpError}
+	case code == http.StatusUnauthorized:
+		return UnauthorizedError{httpError}
+	case code == http.StatusForbidden:
+		return ForbiddenError{httpError}
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		return ValidationError{HTTPError: httpError, Fields: parsed.fields()}
+	case code >= 500:
+		return ServerError{httpError}
+	default:
+		return httpError
+	}
+}
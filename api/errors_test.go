@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func Test_newAPIError(t *testing.T) {
+	u, _ := url.Parse("https://kae.example.com/api/v1/jobs")
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantType   interface{}
+		wantMsg    string
+		wantFields []FieldError
+	}{
+		{name: "not found", statusCode: 404, body: `{"message": "job not found"}`, wantType: NotFoundError{}, wantMsg: "job not found"},
+		{name: "conflict", statusCode: 409, body: `{"error": "job myjob already exists"}`, wantType: ConflictError{}, wantMsg: "job myjob already exists"},
+		{name: "unauthorized", statusCode: 401, body: `not json`, wantType: UnauthorizedError{}},
+		{name: "forbidden", statusCode: 403, body: `{"msg": "permission denied"}`, wantType: ForbiddenError{}, wantMsg: "permission denied"},
+		{
+			name:       "validation with field map",
+			statusCode: 422,
+			body:       `{"message": "invalid job", "errors": {"name": "must not be empty", "image": ["is required", "is invalid"]}}`,
+			wantType:   ValidationError{},
+			wantMsg:    "invalid job",
+			wantFields: []FieldError{
+				{Field: "image", Message: "is required"},
+				{Field: "image", Message: "is invalid"},
+				{Field: "name", Message: "must not be empty"},
+			},
+		},
+		{
+			name:       "validation with field list",
+			statusCode: 400,
+			body:       `{"message": "invalid job", "errors": [{"field": "cluster", "message": "unknown cluster"}]}`,
+			wantType:   ValidationError{},
+			wantMsg:    "invalid job",
+			wantFields: []FieldError{{Field: "cluster", Message: "unknown cluster"}},
+		},
+		{name: "server error", statusCode: 502, body: ``, wantType: ServerError{}},
+		{name: "other", statusCode: 418, body: `{"message": "teapot"}`, wantType: HTTPError{}, wantMsg: "teapot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(HTTPError{StatusCode: tt.statusCode, RequestURL: u}, []byte(tt.body))
+			if reflect.TypeOf(err) != reflect.TypeOf(tt.wantType) {
+				t.Fatalf("error type = %T, want %T", err, tt.wantType)
+			}
+
+			var httpErr HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("%T doesn't unwrap to HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.statusCode || httpErr.Message != tt.wantMsg {
+				t.Errorf("HTTPError = %d %q, want %d %q", httpErr.StatusCode, httpErr.Message, tt.statusCode, tt.wantMsg)
+			}
+
+			var validationErr ValidationError
+			if errors.As(err, &validationErr) && !reflect.DeepEqual(validationErr.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", validationErr.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	u, _ := url.Parse("https://kae.example.com/api/v1/jobs")
+	err := ValidationError{
+		HTTPError: HTTPError{StatusCode: 422, RequestURL: u, Message: "invalid job"},
+		Fields:    []FieldError{{Field: "name", Message: "must not be empty"}, {Message: "spec is malformed"}},
+	}
+	want := "HTTP 422: invalid job (https://kae.example.com/api/v1/jobs)\n  - name: must not be empty\n  - spec is malformed"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
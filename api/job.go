@@ -2,17 +2,18 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/gorilla/websocket"
 	"github.com/kaecloud/kaectl/pkg/spec"
-	"github.com/pkg/errors"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
+	"time"
 )
 
 type JobClient struct {
@@ -20,14 +21,78 @@ type JobClient struct {
 }
 
 type Job struct {
-	Id       int64  `json:"id"`
-	Name     string `orm:"unique" json:"name"`
-	SpecText string `json:"spec_text"`
-	Comment  string `json:"comment"`
+	Id        int64     `json:"id"`
+	Name      string    `orm:"unique" json:"name"`
+	Cluster   string    `json:"cluster"`
+	Status    string    `json:"status"`
+	SpecText  string    `json:"spec_text"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-func NewJobClient(baseUrl string, accessTok string) *JobClient {
-	c := NewClient()
+// Phases of JobStatus and Pod
+const (
+	PhasePending   = "Pending"
+	PhaseRunning   = "Running"
+	PhaseSucceeded = "Succeeded"
+	PhaseFailed    = "Failed"
+)
+
+// JobStatus is the status of the Kubernetes job of a job
+type JobStatus struct {
+	// Phase is one of PhasePending, PhaseRunning, PhaseSucceeded and
+	// PhaseFailed
+	Phase string `json:"phase"`
+	// StartTime and CompletionTime are nil until the job starts and completes
+	StartTime      *time.Time `json:"start_time"`
+	CompletionTime *time.Time `json:"completion_time"`
+	// Active, Succeeded and Failed are the numbers of pods in each state
+	Active     int            `json:"active"`
+	Succeeded  int            `json:"succeeded"`
+	Failed     int            `json:"failed"`
+	Conditions []JobCondition `json:"conditions"`
+}
+
+// JobCondition is a condition of a Kubernetes job, e.g. Complete or Failed
+type JobCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
+	LastTransitionTime time.Time `json:"last_transition_time"`
+}
+
+// Pod is a pod created by a job
+type Pod struct {
+	Name      string    `json:"name"`
+	Phase     string    `json:"phase"`
+	Node      string    `json:"node"`
+	Restarts  int       `json:"restarts"`
+	StartTime time.Time `json:"start_time"`
+	// ExitCode is the exit code of the terminated container, it is nil while
+	// the container is running
+	ExitCode *int `json:"exit_code"`
+	// Reason is the reason of the terminated state of the container, e.g.
+	// OOMKilled or Error, when the container terminated. Otherwise it is the
+	// reason of the pod status, e.g. Evicted, which has no ExitCode as the
+	// container was not terminated by itself.
+	Reason string `json:"reason"`
+}
+
+// Event is a Kubernetes event of a job or of its pods
+type Event struct {
+	// Type is Normal or Warning
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Object is the kind and the name of the object, e.g. Pod/my-job-x7k2p
+	Object        string    `json:"object"`
+	Count         int       `json:"count"`
+	LastTimestamp time.Time `json:"last_timestamp"`
+}
+
+func NewJobClient(httpClient *http.Client, baseUrl string, accessTok string) *JobClient {
+	c := NewClientFromHTTP(httpClient)
 	c.baseUrl = baseUrl
 	c.accessToken = accessTok
 	return &JobClient{
@@ -35,28 +100,82 @@ func NewJobClient(baseUrl string, accessTok string) *JobClient {
 	}
 }
 
-func (c *JobClient) Get(name string) (*Job, error) {
+func (c *JobClient) Get(ctx context.Context, name string) (*Job, error) {
 	path := fmt.Sprintf("/api/v1/jobs/%s", name)
 	var data Job
-	err := c.REST("GET", path, nil, &data)
+	err := c.REST(ctx, "GET", path, nil, &data)
 	return &data, err
 }
 
-func (c *JobClient) List() ([]*Job, error) {
+// List returns the jobs visible to current user, if cluster is not empty,
+// only the jobs in that cluster are returned.
+func (c *JobClient) List(ctx context.Context, cluster string) ([]*Job, error) {
 	path := "/api/v1/jobs"
+	if cluster != "" {
+		path += "?" + url.Values{"cluster": {cluster}}.Encode()
+	}
 	var data []*Job
-	err := c.REST("GET", path, nil, &data)
+	err := c.REST(ctx, "GET", path, nil, &data)
 	return data, err
 }
 
-func (c *JobClient) Delete(name string) error {
+// Pods returns the pods of the job sorted by name
+func (c *JobClient) Pods(ctx context.Context, name string, cluster string) ([]*Pod, error) {
+	path := fmt.Sprintf("/api/v1/jobs/%s/pods", url.PathEscape(name))
+	if cluster != "" {
+		path += "?" + url.Values{"cluster": {cluster}}.Encode()
+	}
+	var data []*Pod
+	err := c.REST(ctx, "GET", path, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].Name < data[j].Name
+	})
+	return data, nil
+}
+
+// Status returns the status of the job
+func (c *JobClient) Status(ctx context.Context, name string, cluster string) (*JobStatus, error) {
+	path := fmt.Sprintf("/api/v1/jobs/%s/status", url.PathEscape(name))
+	if cluster != "" {
+		path += "?" + url.Values{"cluster": {cluster}}.Encode()
+	}
+	var data JobStatus
+	err := c.REST(ctx, "GET", path, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// Events returns the recent events of the job and of its pods, the oldest
+// first
+func (c *JobClient) Events(ctx context.Context, name string, cluster string) ([]*Event, error) {
+	path := fmt.Sprintf("/api/v1/jobs/%s/events", url.PathEscape(name))
+	if cluster != "" {
+		path += "?" + url.Values{"cluster": {cluster}}.Encode()
+	}
+	var data []*Event
+	err := c.REST(ctx, "GET", path, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].LastTimestamp.Before(data[j].LastTimestamp)
+	})
+	return data, nil
+}
+
+func (c *JobClient) Delete(ctx context.Context, name string) error {
 	path := fmt.Sprintf("/api/v1/jobs/%s", name)
 	var data Job
-	err := c.REST("DELETE", path, nil, &data)
+	err := c.REST(ctx, "DELETE", path, nil, &data)
 	return err
 }
 
-func (c *JobClient) Create(args *spec.CreateJobArgs) (*Job, error) {
+func (c *JobClient) Create(ctx context.Context, args *spec.CreateJobArgs) (*Job, error) {
 	path := "/api/v1/jobs"
 	reqBytes, err := json.Marshal(args)
 	if err != nil {
@@ -65,11 +184,11 @@ func (c *JobClient) Create(args *spec.CreateJobArgs) (*Job, error) {
 	reqBody := bytes.NewReader(reqBytes)
 	res := Job{}
 
-	err = c.REST("POST", path, reqBody, &res)
+	err = c.REST(ctx, "POST", path, reqBody, &res)
 	return &res, err
 }
 
-func (c *JobClient) Upload(jobname string, values map[string]io.Reader, respBody interface{}) (err error) {
+func (c *JobClient) Upload(ctx context.Context, jobname string, values map[string]io.Reader, respBody interface{}) (err error) {
 	path := fmt.Sprintf("/api/v1/jobs/%s/artifacts", jobname)
 	uploadURL := c.FullUrl(path)
 
@@ -102,7 +221,9 @@ func (c *JobClient) Upload(jobname string, values map[string]io.Reader, respBody
 	w.Close()
 
 	// Now that you have a form, you can submit it to your handler.
-	req, err := http.NewRequest("POST", uploadURL, &b)
+	// the artifact is stored under its object key, so uploading it twice
+	// is harmless
+	req, err := http.NewRequestWithContext(WithIdempotent(ctx), "POST", uploadURL, &b)
 	if err != nil {
 		return
 	}
@@ -112,7 +233,7 @@ func (c *JobClient) Upload(jobname string, values map[string]io.Reader, respBody
 	return c.rest(req, respBody)
 }
 
-func (c *JobClient) UploadArtifact(jobname string, filename string, objKey string) (objUrl string, err error) {
+func (c *JobClient) UploadArtifact(ctx context.Context, jobname string, filename string, objKey string) (objUrl string, err error) {
 	r, err := os.Open(filename)
 	if err != nil {
 		return "", err
@@ -126,63 +247,6 @@ func (c *JobClient) UploadArtifact(jobname string, filename string, objKey strin
 	var jsonResp struct {
 		Data string `json:"data"`
 	}
-	err = c.Upload(jobname, values, &jsonResp)
+	err = c.Upload(ctx, jobname, values, &jsonResp)
 	return jsonResp.Data, err
 }
-
-func (c *JobClient) Logs(name string, podname string, cluster string, follow bool) (chan interface{}, error) {
-	path := fmt.Sprintf("/api/v1/jobs/%s/log?cluster=%s&podname=%s", name, cluster, podname)
-	if follow {
-		path += "&follow=true"
-	}
-	wsUrl := c.FullWebsocketUrl(path)
-	hdr := http.Header{
-		"Authorization": {"Bearer "+c.accessToken},
-	}
-	ws, _, err := websocket.DefaultDialer.Dial(wsUrl, hdr)
-	if err != nil {
-		log.Fatal("dial:", err)
-	}
-	res := make(chan interface{})
-
-	go func() {
-		defer func() {
-			ws.Close()
-			close(res)
-		}()
-		for {
-			msgType, msgBytes, err := ws.ReadMessage()
-			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-					return
-				}
-				res <- err
-				return
-			}
-			switch msgType {
-			case websocket.PingMessage:
-				if err := ws.WriteMessage(websocket.PongMessage, []byte("PP")); err != nil {
-					res <- err
-					return
-				}
-			case websocket.TextMessage:
-				var data struct {
-					Data string `json:"data"`
-					Error string `json:"error"`
-				}
-				err = json.Unmarshal(msgBytes, &data)
-				if err != nil {
-					res <- err
-					return
-				}
-				if data.Error != "" {
-					res <- errors.New(data.Error)
-				} else {
-					res <- data.Data
-				}
-			}
-		}
-	}()
-	return res, err
-}
-
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJobClient_describe(t *testing.T) {
+	responses := map[string]string{
+		"/api/v1/jobs/my-job/status": `{"phase": "Failed", "start_time": "2020-10-01T12:00:00Z", "succeeded": 1, "failed": 1,
+			"conditions": [{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"}]}`,
+		"/api/v1/jobs/my-job/pods": `[{"name": "my-job-b", "phase": "Failed", "exit_code": 137, "reason": "OOMKilled"},
+			{"name": "my-job-a", "phase": "Running"}]`,
+		"/api/v1/jobs/my-job/events": `[{"type": "Warning", "reason": "BackoffLimitExceeded", "last_timestamp": "2020-10-01T12:30:00Z"},
+			{"type": "Normal", "reason": "SuccessfulCreate", "last_timestamp": "2020-10-01T12:00:00Z"}]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("cluster"); got != "mycluster" {
+			t.Errorf("cluster = %q, want mycluster", got)
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+
+	status, err := c.Status(ctx, "my-job", "mycluster")
+	if err != nil {
+		t.Fatalf("Status: unexpected error: %v", err)
+	}
+	if status.Phase != PhaseFailed || status.StartTime == nil || status.CompletionTime != nil || status.Failed != 1 {
+		t.Errorf("Status = %+v", status)
+	}
+	if len(status.Conditions) != 1 || status.Conditions[0].Reason != "BackoffLimitExceeded" {
+		t.Errorf("Conditions = %+v", status.Conditions)
+	}
+
+	pods, err := c.Pods(ctx, "my-job", "mycluster")
+	if err != nil {
+		t.Fatalf("Pods: unexpected error: %v", err)
+	}
+	if len(pods) != 2 || pods[0].Name != "my-job-a" || pods[1].Name != "my-job-b" {
+		t.Fatalf("Pods = %+v, want my-job-a and my-job-b", pods)
+	}
+	if pods[0].ExitCode != nil {
+		t.Errorf("ExitCode of running pod = %d, want nil", *pods[0].ExitCode)
+	}
+	if pods[1].ExitCode == nil || *pods[1].ExitCode != 137 || pods[1].Reason != "OOMKilled" {
+		t.Errorf("terminated pod = %+v", pods[1])
+	}
+
+	events, err := c.Events(ctx, "my-job", "mycluster")
+	if err != nil {
+		t.Fatalf("Events: unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].Reason != "SuccessfulCreate" || events[1].Reason != "BackoffLimitExceeded" {
+		t.Errorf("Events = %+v, want the oldest first", events)
+	}
+}
@@ -0,0 +1,459 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// LogOptions selects the logs streamed by JobClient.Logs
+type LogOptions struct {
+	Cluster string
+	// Pod is the pod to stream, the server chooses one when it is empty
+	Pod string
+	// Container is the container to stream, it may be omitted when the pod
+	// has only one container
+	Container string
+	// Follow keeps streaming the new lines until the pod exits
+	Follow bool
+	// Tail is the number of recent lines to stream, 0 means all lines
+	Tail int
+	// Since and SinceTime only stream the lines newer than a relative
+	// duration or a time, zero means no limit
+	Since     time.Duration
+	SinceTime time.Time
+	// Timestamps requests the time of every line in LogLine.Time
+	Timestamps bool
+	// Previous streams the log of the previous terminated container, e.g.
+	// the one crashed before a restart
+	Previous bool
+}
+
+// query returns the query parameters of a log stream, a stream resumed
+// after a line at since gets the lines since then.
+func (opts LogOptions) query(since time.Time) url.Values {
+	q := url.Values{}
+	q.Set("cluster", opts.Cluster)
+	q.Set("podname", opts.Pod)
+	if opts.Container != "" {
+		q.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		q.Set("follow", "true")
+	}
+	// timestamps allow resuming after the last line when reconnecting
+	if opts.Follow || opts.Timestamps {
+		q.Set("timestamps", "true")
+	}
+	if opts.Previous {
+		q.Set("previous", "true")
+	}
+	switch {
+	case !since.IsZero():
+		q.Set("since_time", since.Format(time.RFC3339))
+	case !opts.SinceTime.IsZero():
+		q.Set("since_time", opts.SinceTime.Format(time.RFC3339))
+	case opts.Since > 0:
+		secs := int64((opts.Since + time.Second - 1) / time.Second)
+		q.Set("since_seconds", strconv.FormatInt(secs, 10))
+	}
+	if opts.Tail > 0 && since.IsZero() {
+		q.Set("tail", strconv.Itoa(opts.Tail))
+	}
+	return q
+}
+
+// LogLine is a line of the log of a pod
+type LogLine struct {
+	// Pod is the pod which wrote the line, it is empty when the stream was
+	// opened without LogOptions.Pod
+	Pod  string
+	Text string
+	// Time is when the line was written, it is zero when the server didn't
+	// send the timestamp
+	Time time.Time
+}
+
+// LogEvent is an event of the stream returned by JobClient.Logs, either a
+// line, the error ending the stream, or a notice of reconnecting.
+type LogEvent struct {
+	Line LogLine
+	Err  error
+	// Reconnected is set when the stream was reopened after the connection
+	// to the server was lost
+	Reconnected bool
+}
+
+// LogStreamClosedError is returned when the server closes a log stream with
+// a code other than normal closure
+type LogStreamClosedError struct {
+	Code int
+	Text string
+}
+
+func (e *LogStreamClosedError) Error() string {
+	var msg string
+	switch e.Code {
+	case websocket.CloseGoingAway:
+		msg = "the server is going away"
+	case websocket.CloseAbnormalClosure:
+		msg = "lost connection to the server"
+	case websocket.ClosePolicyViolation:
+		msg = "the server refused to stream the log"
+	case websocket.CloseMessageTooBig:
+		msg = "a log line is too long"
+	case websocket.CloseInternalServerErr:
+		msg = "the server failed to stream the log"
+	case websocket.CloseTryAgainLater:
+		msg = "the server is overloaded, try again later"
+	default:
+		msg = fmt.Sprintf("the server closed the log stream with code %d", e.Code)
+	}
+	if e.Text != "" {
+		msg += ": " + e.Text
+	}
+	return msg
+}
+
+// logMessage is a message sent by the server on a log stream
+type logMessage struct {
+	Data  string `json:"data"`
+	Error string `json:"error"`
+}
+
+// Logs streams the log of job, the returned channel is closed when the
+// stream ends. An error ending the stream is sent as the last event, a
+// stream closed normally by the server has no error event. Canceling ctx
+// closes the stream.
+//
+// When following the log, a lost connection is reopened with backoff and
+// the stream resumes after the last line received, an event with
+// Reconnected set is sent after reconnecting. A stream without timestamps
+// opened with Tail or Since is not resumed, see logCursor.resumable.
+func (c *JobClient) Logs(ctx context.Context, name string, opts LogOptions) (<-chan LogEvent, error) {
+	cursor := &logCursor{}
+	ws, err := c.openLog(ctx, name, opts, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan LogEvent)
+	go func() {
+		defer close(events)
+		send := func(ev LogEvent) bool {
+			select {
+			case events <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		err := readLog(ctx, ws, opts, cursor, send)
+		for opts.Follow && isTransientLogError(err) && ctx.Err() == nil {
+			if !cursor.resumable(opts) {
+				err = fmt.Errorf("%w, the log can't be resumed as the server sent no timestamps", err)
+				break
+			}
+			ws, err = c.reopenLog(ctx, name, opts, cursor)
+			if err != nil {
+				break
+			}
+			if !send(LogEvent{Reconnected: true}) {
+				ws.Close()
+				return
+			}
+			err = readLog(ctx, ws, opts, cursor, send)
+		}
+		if err != nil && ctx.Err() == nil {
+			send(LogEvent{Err: err})
+		}
+	}()
+	return events, nil
+}
+
+// MergeLogStreams interleaves the events of the log streams of several pods,
+// streams maps the name of a pod to its stream. The errors ending the streams
+// are combined into the last event of the merged stream. Canceling ctx stops
+// merging and closes the merged stream, the streams should be opened with the
+// same ctx so they are closed too.
+func MergeLogStreams(ctx context.Context, streams map[string]<-chan LogEvent) <-chan LogEvent {
+	merged := make(chan LogEvent)
+	send := func(ev LogEvent) bool {
+		select {
+		case merged <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for pod, events := range streams {
+		wg.Add(1)
+		go func(pod string, events <-chan LogEvent) {
+			defer wg.Done()
+			for {
+				var ev LogEvent
+				var ok bool
+				select {
+				case ev, ok = <-events:
+					if !ok {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+				if ev.Err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("pod %s: %w", pod, ev.Err))
+					mu.Unlock()
+					continue
+				}
+				if ev.Line.Pod == "" {
+					ev.Line.Pod = pod
+				}
+				if !send(ev) {
+					return
+				}
+			}
+		}(pod, events)
+	}
+	go func() {
+		defer close(merged)
+		wg.Wait()
+		switch len(errs) {
+		case 0:
+		case 1:
+			send(LogEvent{Err: errs[0]})
+		default:
+			msgs := make([]string, len(errs))
+			for i, err := range errs {
+				msgs[i] = err.Error()
+			}
+			sort.Strings(msgs)
+			send(LogEvent{Err: errors.New(strings.Join(msgs, "\n"))})
+		}
+	}()
+	return merged
+}
+
+// logReconnectPolicy is the backoff of reopening a followed log stream
+var logReconnectPolicy = RetryPolicy{
+	MaxRetries: 8,
+	MinDelay:   time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+func (c *JobClient) openLog(ctx context.Context, name string, opts LogOptions, cursor *logCursor) (*websocket.Conn, error) {
+	path := fmt.Sprintf("/api/v1/jobs/%s/log?%s", url.PathEscape(name), opts.query(cursor.since()).Encode())
+	hdr := http.Header{
+		"Authorization": {"Bearer " + c.accessToken},
+	}
+	return c.dialWebsocket(ctx, c.FullWebsocketUrl(path), hdr)
+}
+
+// reopenLog reopens a lost log stream with backoff
+func (c *JobClient) reopenLog(ctx context.Context, name string, opts LogOptions, cursor *logCursor) (*websocket.Conn, error) {
+	for attempt := 0; ; attempt++ {
+		timer := time.NewTimer(logReconnectPolicy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		cursor.rewind()
+		ws, err := c.openLog(ctx, name, opts, cursor)
+		if err == nil || !isTransientLogError(err) || attempt+1 >= logReconnectPolicy.MaxRetries {
+			return ws, err
+		}
+	}
+}
+
+// isTransientLogError reports whether a log stream ended by err can be
+// resumed by reconnecting
+func isTransientLogError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var closedErr *LogStreamClosedError
+	if errors.As(err, &closedErr) {
+		switch closedErr.Code {
+		case websocket.CloseGoingAway, websocket.CloseAbnormalClosure, websocket.CloseServiceRestart, websocket.CloseTryAgainLater:
+			return true
+		}
+		return false
+	}
+	if errors.As(err, &ServerError{}) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// logCursor tracks the position in a log stream to resume it without
+// duplicating or losing lines. The position is the timestamp of the last
+// line when the server sends timestamps, or the number of lines otherwise.
+type logCursor struct {
+	// lines is the number of lines received
+	lines int
+	// last is the timestamp of the last line, atLast is the number of
+	// lines with this timestamp
+	last   time.Time
+	atLast int
+	// untimed is set once a line without timestamp is received
+	untimed bool
+
+	// resuming is set after reconnecting until the first new line, skip is
+	// the number of lines to drop as the server sends them again
+	resuming bool
+	skip     int
+}
+
+// since returns the time to resume the stream from, it is zero when the
+// stream must be read from the start
+func (cur *logCursor) since() time.Time {
+	if cur.untimed {
+		return time.Time{}
+	}
+	return cur.last
+}
+
+// resumable reports whether the stream can be resumed without losing lines.
+// Without timestamps the lines already received are skipped by number, which
+// requires the server to send the same lines again. Tail and Since select
+// the lines relative to the time of the request, so the reopened stream
+// starts later and skipping by number would drop new lines.
+func (cur *logCursor) resumable(opts LogOptions) bool {
+	return !cur.untimed || (opts.Tail == 0 && opts.Since == 0)
+}
+
+// rewind prepares the cursor for reading the stream again from since()
+func (cur *logCursor) rewind() {
+	cur.resuming = true
+	if cur.untimed {
+		cur.skip = cur.lines
+	} else {
+		cur.skip = cur.atLast
+	}
+}
+
+// next updates the cursor with a line read from the stream and reports
+// whether it is new
+func (cur *logCursor) next(line *LogLine) bool {
+	if cur.resuming {
+		if cur.untimed {
+			if cur.skip > 0 {
+				cur.skip--
+				return false
+			}
+		} else if !line.Time.IsZero() {
+			// the server resends the lines since the second of the last line
+			if line.Time.Before(cur.last) {
+				return false
+			}
+			if line.Time.Equal(cur.last) && cur.skip > 0 {
+				cur.skip--
+				return false
+			}
+		}
+		cur.resuming = false
+	}
+
+	cur.lines++
+	if line.Time.IsZero() {
+		cur.untimed = true
+	} else if line.Time.Equal(cur.last) {
+		cur.atLast++
+	} else {
+		cur.last = line.Time
+		cur.atLast = 1
+	}
+	return true
+}
+
+// splitTimestamp splits the RFC3339 timestamp the server prepends to a line
+// when timestamps are requested
+func splitTimestamp(text string) (time.Time, string) {
+	i := strings.IndexByte(text, ' ')
+	if i < 0 {
+		return time.Time{}, text
+	}
+	t, err := time.Parse(time.RFC3339Nano, text[:i])
+	if err != nil {
+		return time.Time{}, text
+	}
+	return t, text[i+1:]
+}
+
+// readLog sends the lines read from ws until the stream ends, it returns nil
+// when the server closed the stream normally.
+func readLog(ctx context.Context, ws *websocket.Conn, opts LogOptions, cursor *logCursor, send func(LogEvent) bool) error {
+	done := make(chan struct{})
+	defer close(done)
+	// close the connection when ctx is done, this unblocks ReadMessage
+	go func() {
+		select {
+		case <-ctx.Done():
+			deadline := time.Now().Add(time.Second)
+			msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			_ = ws.WriteControl(websocket.CloseMessage, msg, deadline)
+			ws.Close()
+		case <-done:
+			ws.Close()
+		}
+	}()
+
+	for {
+		// pings are answered by the default ping handler while reading
+		msgType, data, err := ws.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) {
+				if closeErr.Code == websocket.CloseNormalClosure {
+					return nil
+				}
+				return &LogStreamClosedError{Code: closeErr.Code, Text: closeErr.Text}
+			}
+			return err
+		}
+		if msgType != websocket.TextMessage {
+			continue
+		}
+
+		var msg logMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("invalid log message: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		line := LogLine{Pod: opts.Pod, Text: strings.TrimSuffix(msg.Data, "\n")}
+		if opts.Follow || opts.Timestamps {
+			line.Time, line.Text = splitTimestamp(line.Text)
+		}
+		if !cursor.next(&line) {
+			continue
+		}
+		if !send(LogEvent{Line: line}) {
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newLogServer returns a server streaming messages on the log websocket of
+// myjob and closing it with closeCode
+func newLogServer(t *testing.T, messages []string, closeCode int) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/jobs/myjob/log" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "job not found"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer TOKEN" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer ws.Close()
+		for _, msg := range messages {
+			if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+		if closeCode != 0 {
+			msg := websocket.FormatCloseMessage(closeCode, "")
+			ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		}
+		// wait for the client to close the connection
+		ws.ReadMessage()
+	}))
+}
+
+func collectLogs(events <-chan LogEvent) ([]string, error) {
+	var lines []string
+	var err error
+	for ev := range events {
+		if ev.Err != nil {
+			err = ev.Err
+			continue
+		}
+		lines = append(lines, ev.Line.Text)
+	}
+	return lines, err
+}
+
+func TestJobClient_Logs(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []string
+		closeCode int
+		wantLines []string
+		wantErr   string
+	}{
+		{
+			name:      "normal closure",
+			messages:  []string{`{"data": "hello"}`, `{"data": "world\n"}`},
+			closeCode: websocket.CloseNormalClosure,
+			wantLines: []string{"hello", "world"},
+		},
+		{
+			name:      "server error",
+			messages:  []string{`{"data": "hello"}`},
+			closeCode: websocket.CloseInternalServerErr,
+			wantLines: []string{"hello"},
+			wantErr:   "the server failed to stream the log",
+		},
+		{
+			name:      "error message",
+			messages:  []string{`{"data": "hello"}`, `{"error": "pod not found"}`},
+			wantLines: []string{"hello"},
+			wantErr:   "pod not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newLogServer(t, tt.messages, tt.closeCode)
+			defer srv.Close()
+
+			c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+			events, err := c.Logs(context.Background(), "myjob", LogOptions{Cluster: "mycluster"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			lines, err := collectLogs(events)
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJobClient_Logs_notFound(t *testing.T) {
+	srv := newLogServer(t, nil, 0)
+	defer srv.Close()
+
+	c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+	_, err := c.Logs(context.Background(), "nojob", LogOptions{})
+	if !errors.As(err, &NotFoundError{}) {
+		t.Errorf("error = %v, want NotFoundError", err)
+	}
+}
+
+func TestJobClient_Logs_canceled(t *testing.T) {
+	srv := newLogServer(t, []string{`{"data": "hello"}`}, 0)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+	events, err := c.Logs(ctx, "myjob", LogOptions{Follow: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev := <-events; ev.Line.Text != "hello" {
+		t.Errorf("first event = %+v, want line hello", ev)
+	}
+	cancel()
+	if _, err := collectLogs(events); err != nil {
+		t.Errorf("unexpected error after cancel: %v", err)
+	}
+}
+
+func TestJobClient_Logs_reconnect(t *testing.T) {
+	policy := logReconnectPolicy
+	logReconnectPolicy = RetryPolicy{MaxRetries: 2, MinDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	defer func() { logReconnectPolicy = policy }()
+
+	var mu sync.Mutex
+	var sinceTimes []string
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("follow") != "true" || q.Get("timestamps") != "true" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		mu.Lock()
+		sinceTimes = append(sinceTimes, q.Get("since_time"))
+		first := len(sinceTimes) == 1
+		mu.Unlock()
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer ws.Close()
+
+		var messages []string
+		if first {
+			messages = []string{
+				`{"data": "2020-10-01T12:00:00.1Z one"}`,
+				`{"data": "2020-10-01T12:00:01.2Z two"}`,
+				`{"data": "2020-10-01T12:00:01.2Z three"}`,
+			}
+		} else {
+			// the server resends the lines since the second of since_time
+			messages = []string{
+				`{"data": "2020-10-01T12:00:01.1Z zero"}`,
+				`{"data": "2020-10-01T12:00:01.2Z two"}`,
+				`{"data": "2020-10-01T12:00:01.2Z three"}`,
+				`{"data": "2020-10-01T12:00:01.2Z four"}`,
+				`{"data": "2020-10-01T12:00:02Z five"}`,
+			}
+		}
+		for _, msg := range messages {
+			ws.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		if first {
+			// drop the connection without a close frame
+			return
+		}
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		ws.ReadMessage()
+	}))
+	defer srv.Close()
+
+	c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+	events, err := c.Logs(context.Background(), "myjob", LogOptions{Follow: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lines []string
+	reconnected := 0
+	for ev := range events {
+		switch {
+		case ev.Err != nil:
+			t.Errorf("unexpected error: %v", ev.Err)
+		case ev.Reconnected:
+			reconnected++
+		default:
+			lines = append(lines, ev.Line.Text)
+		}
+	}
+
+	if want := []string{"one", "two", "three", "four", "five"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if reconnected != 1 {
+		t.Errorf("reconnected %d times, want 1", reconnected)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"", "2020-10-01T12:00:01Z"}; !reflect.DeepEqual(sinceTimes, want) {
+		t.Errorf("since_time = %q, want %q", sinceTimes, want)
+	}
+}
+
+func TestJobClient_Logs_reconnectUntimed(t *testing.T) {
+	policy := logReconnectPolicy
+	logReconnectPolicy = RetryPolicy{MaxRetries: 2, MinDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	defer func() { logReconnectPolicy = policy }()
+
+	tests := []struct {
+		name         string
+		opts         LogOptions
+		wantLines    []string
+		wantErr      string
+		wantRequests int
+	}{
+		{
+			name:         "resumed",
+			opts:         LogOptions{Follow: true},
+			wantLines:    []string{"one", "two", "three"},
+			wantRequests: 2,
+		},
+		{
+			// the reopened stream would get the last line only, skipping
+			// the 2 lines received would drop it
+			name:         "tail",
+			opts:         LogOptions{Follow: true, Tail: 2},
+			wantLines:    []string{"one", "two"},
+			wantErr:      "the server is going away, the log can't be resumed as the server sent no timestamps",
+			wantRequests: 1,
+		},
+		{
+			name:         "since",
+			opts:         LogOptions{Follow: true, Since: time.Minute},
+			wantLines:    []string{"one", "two"},
+			wantErr:      "the server is going away, the log can't be resumed as the server sent no timestamps",
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := 0
+			upgrader := websocket.Upgrader{}
+			// the server ignores timestamps=true
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests++
+				first := requests == 1
+				mu.Unlock()
+				ws, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				defer ws.Close()
+				if first {
+					ws.WriteMessage(websocket.TextMessage, []byte(`{"data": "one"}`))
+					ws.WriteMessage(websocket.TextMessage, []byte(`{"data": "two"}`))
+					// close the stream as a restarting server does
+					ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+					return
+				}
+				for _, msg := range []string{`{"data": "one"}`, `{"data": "two"}`, `{"data": "three"}`} {
+					ws.WriteMessage(websocket.TextMessage, []byte(msg))
+				}
+				msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+				ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+				ws.ReadMessage()
+			}))
+			defer srv.Close()
+
+			c := NewJobClient(NewHTTPClient(), srv.URL, "TOKEN")
+			events, err := c.Logs(context.Background(), "myjob", tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var lines []string
+			for ev := range events {
+				switch {
+				case ev.Err != nil:
+					err = ev.Err
+				case !ev.Reconnected:
+					lines = append(lines, ev.Line.Text)
+				}
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %q, want %q", lines, tt.wantLines)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func Test_logCursor_untimed(t *testing.T) {
+	cur := &logCursor{}
+	for _, text := range []string{"a", "b"} {
+		if !cur.next(&LogLine{Text: text}) {
+			t.Errorf("line %q dropped", text)
+		}
+	}
+	cur.rewind()
+	if !cur.since().IsZero() {
+		t.Errorf("since() = %v, want zero", cur.since())
+	}
+	var got []string
+	for _, text := range []string{"a", "b", "c"} {
+		if cur.next(&LogLine{Text: text}) {
+			got = append(got, text)
+		}
+	}
+	if want := []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines after rewind = %q, want %q", got, want)
+	}
+}
+
+func TestLogOptions_query(t *testing.T) {
+	sinceTime := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		opts  LogOptions
+		since time.Time
+		want  string
+	}{
+		{
+			name: "defaults",
+			opts: LogOptions{Cluster: "mycluster"},
+			want: "cluster=mycluster&podname=",
+		},
+		{
+			name: "all options",
+			opts: LogOptions{Cluster: "c&1", Pod: "my pod", Container: "main", Tail: 10, Since: 90*time.Second + time.Millisecond, Timestamps: true, Previous: true},
+			want: "cluster=c%261&container=main&podname=my+pod&previous=true&since_seconds=91&tail=10&timestamps=true",
+		},
+		{
+			name: "since time",
+			opts: LogOptions{SinceTime: sinceTime, Since: time.Minute},
+			want: "cluster=&podname=&since_time=2020-10-01T12%3A00%3A00Z",
+		},
+		{
+			name:  "resumed",
+			opts:  LogOptions{Follow: true, Tail: 10, Since: time.Minute},
+			since: sinceTime.Add(time.Hour),
+			want:  "cluster=&follow=true&podname=&since_time=2020-10-01T13%3A00%3A00Z&timestamps=true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.query(tt.since).Encode(); got != tt.want {
+				t.Errorf("query() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLogStreams(t *testing.T) {
+	stream := func(events ...LogEvent) <-chan LogEvent {
+		ch := make(chan LogEvent, len(events))
+		for _, ev := range events {
+			ch <- ev
+		}
+		close(ch)
+		return ch
+	}
+	merged := MergeLogStreams(context.Background(), map[string]<-chan LogEvent{
+		"pod-a": stream(LogEvent{Line: LogLine{Text: "a1"}}, LogEvent{Line: LogLine{Text: "a2"}}),
+		"pod-b": stream(LogEvent{Line: LogLine{Text: "b1"}}, LogEvent{Err: errors.New("pod not found")}),
+		"pod-c": stream(LogEvent{Err: errors.New("lost connection to the server")}),
+	})
+
+	got := map[string][]string{}
+	var err error
+	for ev := range merged {
+		if ev.Err != nil {
+			err = ev.Err
+			continue
+		}
+		got[ev.Line.Pod] = append(got[ev.Line.Pod], ev.Line.Text)
+	}
+	want := map[string][]string{"pod-a": {"a1", "a2"}, "pod-b": {"b1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	wantErr := "pod pod-b: pod not found\npod pod-c: lost connection to the server"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("error = %v, want %q", err, wantErr)
+	}
+}
+
+func TestMergeLogStreams_canceled(t *testing.T) {
+	// the stream is never closed, canceling must close the merged stream
+	events := make(chan LogEvent, 1)
+	events <- LogEvent{Line: LogLine{Text: "a1"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	merged := MergeLogStreams(ctx, map[string]<-chan LogEvent{"pod-a": events})
+	if ev := <-merged; ev.Line.Text != "a1" || ev.Line.Pod != "pod-a" {
+		t.Errorf("first event = %+v, want line a1 of pod-a", ev)
+	}
+	cancel()
+	select {
+	case _, ok := <-merged:
+		if ok {
+			t.Errorf("unexpected event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("merged stream not closed after cancel")
+	}
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"github.com/Nerzal/gocloak/v7"
 	"github.com/kaecloud/kaectl/internal/config"
 	"strings"
@@ -9,13 +10,98 @@ import (
 
 type Token gocloak.JWT
 
-func GetAccessToken(cfg *config.CmdConfig) (*Token, error){
+// EnvToken is the environment variable holding an access token, it is
+// used as is and bypasses any login.
+const EnvToken = "KAE_TOKEN"
+
+// ErrNotLoggedIn is returned when there is neither a usable cached token
+// nor credentials to log in with.
+var ErrNotLoggedIn = errors.New("not logged in, run `kaectl auth login` to authenticate")
+
+// ssoURL returns the base URL of SSO server, https is assumed when
+// sso_host has no scheme.
+func ssoURL(cfg *config.CmdConfig) string {
 	url := cfg.SSOHost
-	if ! strings.HasPrefix(url, "https://") {
+	if ! strings.HasPrefix(url, "https://") && ! strings.HasPrefix(url, "http://") {
 		url = "https://" + url
 	}
-	client := gocloak.NewClient(url)
-	ctx := context.Background()
+	return strings.TrimRight(url, "/")
+}
+
+func newSSOClient(cfg *config.CmdConfig) (gocloak.GoCloak, error) {
+	httpClient, err := ssoHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client := gocloak.NewClient(ssoURL(cfg))
+	client.RestyClient().SetTransport(httpClient.Transport)
+	return client, nil
+}
+
+func GetAccessToken(ctx context.Context, cfg *config.CmdConfig) (*Token, error){
+	client, err := newSSOClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 	token, err := client.Login(ctx, cfg.SSOClientID, "", cfg.SSORealm, cfg.SSOUsername, cfg.SSOPassword)
 	return (*Token)(token), err
-}
\ No newline at end of file
+}
+
+// GetClientAccessToken logs in as the service account of the client with
+// the client credentials grant.
+func GetClientAccessToken(ctx context.Context, cfg *config.CmdConfig) (*Token, error) {
+	client, err := newSSOClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	token, err := client.LoginClient(ctx, cfg.SSOClientID, cfg.SSOClientSecret, cfg.SSORealm)
+	return (*Token)(token), err
+}
+
+// RefreshAccessToken exchanges the refresh token for a new token
+func RefreshAccessToken(ctx context.Context, cfg *config.CmdConfig, refreshToken string) (*Token, error) {
+	client, err := newSSOClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	token, err := client.RefreshToken(ctx, refreshToken, cfg.SSOClientID, cfg.SSOClientSecret, cfg.SSORealm)
+	return (*Token)(token), err
+}
+
+// login gets a new token with the credentials in config, the client
+// credentials take precedence over the user's password.
+func login(ctx context.Context, cfg *config.CmdConfig) (*Token, error) {
+	switch {
+	case cfg.SSOClientSecret != "":
+		return GetClientAccessToken(ctx, cfg)
+	case cfg.SSOPassword != "":
+		return GetAccessToken(ctx, cfg)
+	default:
+		return nil, ErrNotLoggedIn
+	}
+}
+
+// GetCachedAccessToken returns the token in cache while it is valid,
+// renews it with the refresh token when it has expired, and only falls
+// back to a login with the client secret or password when the refresh fails.
+func GetCachedAccessToken(ctx context.Context, cfg *config.CmdConfig, cache *TokenCache) (*Token, error) {
+	if cached, err := cache.Load(cfg); err == nil {
+		if cached.Valid() {
+			return &cached.Token, nil
+		}
+		if cached.Refreshable() {
+			if tok, err := RefreshAccessToken(ctx, cfg, cached.RefreshToken); err == nil {
+				// failing to persist the token only costs a login next time
+				_ = cache.Save(NewCachedToken(cfg, tok))
+				return tok, nil
+			}
+		}
+	}
+
+	tok, err := login(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	_ = cache.Save(NewCachedToken(cfg, tok))
+	return tok, nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/utils"
+)
+
+const DefaultTokenCacheFile = "~/.kae/token.json"
+
+// ContextTokenCacheFile is the token cache of a named context
+const ContextTokenCacheFile = "~/.kae/tokens/%s.json"
+
+// expirySkew is subtracted from the token lifetime, so a token is never
+// used when it is about to expire in the middle of a command.
+const expirySkew = 30 * time.Second
+
+// CachedToken is a Token together with its absolute expiry times and the
+// SSO settings it was issued for.
+type CachedToken struct {
+	Token
+	Issuer           string    `json:"issuer"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at,omitempty"`
+}
+
+// NewCachedToken converts the relative lifetimes of tok to absolute times
+func NewCachedToken(cfg *config.CmdConfig, tok *Token) *CachedToken {
+	now := time.Now()
+	cached := &CachedToken{
+		Token:     *tok,
+		Issuer:    issuerKey(cfg),
+		ExpiresAt: now.Add(time.Duration(tok.ExpiresIn) * time.Second),
+	}
+	// a zero refresh_expires_in means an offline token which never expires
+	if tok.RefreshExpiresIn > 0 {
+		cached.RefreshExpiresAt = now.Add(time.Duration(tok.RefreshExpiresIn) * time.Second)
+	}
+	return cached
+}
+
+// Valid reports whether the access token can still be used
+func (t *CachedToken) Valid() bool {
+	return t.AccessToken != "" && time.Now().Add(expirySkew).Before(t.ExpiresAt)
+}
+
+// Refreshable reports whether the refresh token can still be used
+func (t *CachedToken) Refreshable() bool {
+	if t.RefreshToken == "" {
+		return false
+	}
+	return t.RefreshExpiresAt.IsZero() || time.Now().Add(expirySkew).Before(t.RefreshExpiresAt)
+}
+
+// TokenCache persists tokens in a file only readable by current user
+type TokenCache struct {
+	Path string
+}
+
+func NewTokenCache(filename string) *TokenCache {
+	return &TokenCache{Path: utils.ExpandUser(filename)}
+}
+
+// Load returns the cached token, os.ErrNotExist is returned when there
+// is no cached token for the SSO settings in cfg.
+func (c *TokenCache) Load(cfg *config.CmdConfig) (*CachedToken, error) {
+	data, err := ioutil.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	var tok CachedToken
+	if err := json.Unmarshal(data, &tok); err != nil {
+		return nil, err
+	}
+	if tok.Issuer != issuerKey(cfg) {
+		return nil, os.ErrNotExist
+	}
+	return &tok, nil
+}
+
+// Save writes tok to the cache file atomically with 0600 permissions
+func (c *TokenCache) Save(tok *CachedToken) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(c.Path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// TempFile creates the file with 0600 permissions
+	f, err := ioutil.TempFile(dir, ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.Path)
+}
+
+// Clear removes the cache file, it's not an error if the file doesn't exist
+func (c *TokenCache) Clear() error {
+	err := os.Remove(c.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func issuerKey(cfg *config.CmdConfig) string {
+	return ssoURL(cfg) + "/" + cfg.SSORealm + "/" + cfg.SSOClientID
+}
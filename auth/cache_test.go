@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kaecloud/kaectl/internal/config"
+)
+
+func TestTokenCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kaectl-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.CmdConfig{SSOHost: "sso.example.com", SSORealm: "kae", SSOClientID: "kae-cli"}
+	cache := NewTokenCache(filepath.Join(dir, "nested", "token.json"))
+
+	if _, err := cache.Load(cfg); !os.IsNotExist(err) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+
+	tok := NewCachedToken(cfg, &Token{AccessToken: "ACCESS", RefreshToken: "REFRESH", ExpiresIn: 300, RefreshExpiresIn: 1800})
+	if err := cache.Save(tok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(cache.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("expected permissions 0600, got %o", perm)
+		}
+	}
+
+	loaded, err := cache.Load(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.AccessToken != "ACCESS" || loaded.RefreshToken != "REFRESH" {
+		t.Errorf("unexpected token: %+v", loaded)
+	}
+	if !loaded.Valid() || !loaded.Refreshable() {
+		t.Errorf("expected token to be valid and refreshable")
+	}
+
+	other := *cfg
+	other.SSORealm = "other"
+	if _, err := cache.Load(&other); !os.IsNotExist(err) {
+		t.Errorf("expected token of another realm to be ignored, got %v", err)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.Clear(); err != nil {
+		t.Errorf("clearing a missing cache should succeed, got %v", err)
+	}
+}
+
+func TestCachedToken_Expiry(t *testing.T) {
+	tok := &CachedToken{
+		Token:     Token{AccessToken: "ACCESS", RefreshToken: "REFRESH"},
+		ExpiresAt: time.Now().Add(10 * time.Second),
+	}
+	if tok.Valid() {
+		t.Errorf("token expiring within the skew should not be valid")
+	}
+	if !tok.Refreshable() {
+		t.Errorf("refresh token without expiry should be refreshable")
+	}
+
+	tok.RefreshExpiresAt = time.Now().Add(-time.Minute)
+	if tok.Refreshable() {
+		t.Errorf("expired refresh token should not be refreshable")
+	}
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Claims are the claims of a Keycloak access token used by kaectl
+type Claims struct {
+	Subject           string `json:"sub"`
+	Issuer            string `json:"iss"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Email             string `json:"email"`
+	AuthorizedParty   string `json:"azp"`
+	IssuedAt          int64  `json:"iat"`
+	ExpiresAt         int64  `json:"exp"`
+	RealmAccess       struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+	ResourceAccess map[string]struct {
+		Roles []string `json:"roles"`
+	} `json:"resource_access"`
+}
+
+// DecodeClaims decodes the payload of a JWT access token. The signature is
+// NOT verified, the claims must only be used for display.
+func DecodeClaims(accessToken string) (*Claims, error) {
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed access token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed access token")
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.Wrap(err, "malformed access token")
+	}
+	return &claims, nil
+}
+
+// Realm returns the realm name, which is the last path segment of issuer
+func (c *Claims) Realm() string {
+	return c.Issuer[strings.LastIndex(c.Issuer, "/")+1:]
+}
+
+// Roles returns the realm roles followed by the roles of the client the
+// token was issued to.
+func (c *Claims) Roles() []string {
+	roles := append([]string{}, c.RealmAccess.Roles...)
+	if client, ok := c.ResourceAccess[c.AuthorizedParty]; ok {
+		roles = append(roles, client.Roles...)
+	}
+	return roles
+}
+
+// Username returns the most readable identity in the claims
+func (c *Claims) Username() string {
+	if c.PreferredUsername != "" {
+		return c.PreferredUsername
+	}
+	return c.Subject
+}
+
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
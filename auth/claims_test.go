@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeClaims(t *testing.T) {
+	payload := `{"sub":"1234","iss":"https://sso.example.com/auth/realms/kae","preferred_username":"alice","azp":"kae-cli","exp":1600000000,` +
+		`"realm_access":{"roles":["user"]},"resource_access":{"kae-cli":{"roles":["deployer"]},"other":{"roles":["admin"]}}}`
+	token := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+
+	claims, err := DecodeClaims(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.Username() != "alice" {
+		t.Errorf("expected username alice, got %q", claims.Username())
+	}
+	if claims.Realm() != "kae" {
+		t.Errorf("expected realm kae, got %q", claims.Realm())
+	}
+	if roles := claims.Roles(); !reflect.DeepEqual(roles, []string{"user", "deployer"}) {
+		t.Errorf("unexpected roles: %v", roles)
+	}
+	if claims.Expiry().Unix() != 1600000000 {
+		t.Errorf("unexpected expiry: %v", claims.Expiry())
+	}
+
+	if _, err := DecodeClaims("not-a-jwt"); err == nil {
+		t.Errorf("expected error for malformed token")
+	}
+}
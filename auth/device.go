@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/pkg/errors"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceCode is the response of the device authorization endpoint
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceLoginOptions configures LoginWithDevice
+type DeviceLoginOptions struct {
+	// Prompt is called with the device code, it should tell the user where
+	// to enter the user code.
+	Prompt func(code *DeviceCode) error
+}
+
+// LoginWithDevice runs the OAuth device authorization flow: the user
+// approves the login on another device while we poll the token endpoint.
+func LoginWithDevice(ctx context.Context, cfg *config.CmdConfig, opts DeviceLoginOptions) (*Token, error) {
+	code, err := RequestDeviceCode(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Prompt(code); err != nil {
+		return nil, err
+	}
+	return PollDeviceToken(ctx, cfg, code)
+}
+
+// RequestDeviceCode starts a device authorization
+func RequestDeviceCode(ctx context.Context, cfg *config.CmdConfig) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {cfg.SSOClientID},
+		"scope":     {"openid"},
+	}
+	status, body, err := postForm(ctx, cfg, "auth/device", form)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, oauthError(status, body, "start device authorization")
+	}
+	var code DeviceCode
+	if err := json.Unmarshal(body, &code); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// PollDeviceToken polls the token endpoint until the user approves or
+// denies the device authorization, or the device code expires.
+func PollDeviceToken(ctx context.Context, cfg *config.CmdConfig, code *DeviceCode) (*Token, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"client_id":   {cfg.SSOClientID},
+		"device_code": {code.DeviceCode},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, errors.New("the device code has expired, please login again")
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		tok, err := requestToken(ctx, cfg, form)
+		var oauthErr *OAuthError
+		if !errors.As(err, &oauthErr) {
+			return tok, err
+		}
+		switch oauthErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, errors.New("the login request was denied")
+		case "expired_token":
+			return nil, errors.New("the device code has expired, please login again")
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/kaecloud/kaectl/internal/config"
+)
+
+// newFakeSSOServer returns a stand-in for the authorization, device
+// authorization and token endpoints of Keycloak, tokenResponses are replied
+// in order. The authorization endpoint redirects the browser with the code
+// CODE, which is exchanged only with the verifier of its PKCE challenge.
+func newFakeSSOServer(t *testing.T, tokenResponses []interface{}) (*httptest.Server, *config.CmdConfig) {
+	var (
+		mu          sync.Mutex
+		challenge   string
+		redirectURI string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/realms/kae/protocol/openid-connect/auth", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("client_id") != "kae-cli" || q.Get("code_challenge_method") != "S256" || q.Get("response_type") != "code" {
+			t.Errorf("unexpected authorization request: %v", q)
+		}
+		mu.Lock()
+		challenge, redirectURI = q.Get("code_challenge"), q.Get("redirect_uri")
+		mu.Unlock()
+		target := redirectURI + "?" + url.Values{"code": {"CODE"}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+	mux.HandleFunc("/auth/realms/kae/protocol/openid-connect/auth/device", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != "kae-cli" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "DEVICE",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://sso.example.com/device",
+			ExpiresIn:       60,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("/auth/realms/kae/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		form := r.PostForm
+		switch form.Get("grant_type") {
+		case deviceCodeGrantType:
+			if form.Get("device_code") != "DEVICE" {
+				t.Errorf("unexpected token request: %v", form)
+			}
+		case "authorization_code":
+			mu.Lock()
+			if form.Get("code") != "CODE" || form.Get("redirect_uri") != redirectURI || pkceChallenge(form.Get("code_verifier")) != challenge {
+				t.Errorf("unexpected token request: %v", form)
+			}
+			mu.Unlock()
+		default:
+			t.Errorf("unexpected token request: %v", form)
+		}
+		if len(tokenResponses) == 0 {
+			t.Errorf("too many token requests")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		resp := tokenResponses[0]
+		tokenResponses = tokenResponses[1:]
+		if _, ok := resp.(*OAuthError); ok {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+	srv := httptest.NewServer(mux)
+	cfg := &config.CmdConfig{SSOHost: srv.URL, SSORealm: "kae", SSOClientID: "kae-cli"}
+	return srv, cfg
+}
+
+func TestLoginWithDevice(t *testing.T) {
+	srv, cfg := newFakeSSOServer(t, []interface{}{
+		&OAuthError{Code: "authorization_pending"},
+		&Token{AccessToken: "ACCESS", RefreshToken: "REFRESH", ExpiresIn: 300},
+	})
+	defer srv.Close()
+
+	var prompted *DeviceCode
+	tok, err := LoginWithDevice(context.Background(), cfg, DeviceLoginOptions{
+		Prompt: func(code *DeviceCode) error {
+			prompted = code
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prompted == nil || prompted.UserCode != "ABCD-EFGH" {
+		t.Errorf("expected user code to be prompted, got %+v", prompted)
+	}
+	if tok.AccessToken != "ACCESS" || tok.RefreshToken != "REFRESH" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+}
+
+func TestLoginWithDevice_denied(t *testing.T) {
+	srv, cfg := newFakeSSOServer(t, []interface{}{
+		&OAuthError{Code: "access_denied"},
+	})
+	defer srv.Close()
+
+	_, err := LoginWithDevice(context.Background(), cfg, DeviceLoginOptions{
+		Prompt: func(*DeviceCode) error { return nil },
+	})
+	if err == nil || err.Error() != "the login request was denied" {
+		t.Errorf("expected denied error, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/pkg/errors"
+)
+
+// OAuthError is the error response of the SSO token endpoint
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+func openIDConnectURL(cfg *config.CmdConfig, endpoint string) string {
+	return fmt.Sprintf("%s/auth/realms/%s/protocol/openid-connect/%s", ssoURL(cfg), url.PathEscape(cfg.SSORealm), endpoint)
+}
+
+// ssoHTTPClient returns the http client talking to the SSO server, it
+// connects with the TLS and proxy settings in cfg and logs the requests when
+// the DEBUG environment variable is set.
+func ssoHTTPClient(cfg *config.CmdConfig) (*http.Client, error) {
+	transportOpts, err := api.TransportOptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := api.NewTransport(transportOpts)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewHTTPClient(api.ReplaceTripper(tr), api.VerboseLogFromEnv(os.Stderr)), nil
+}
+
+// postForm posts form to an OpenID Connect endpoint of the SSO server
+// and returns the status code and body of the response.
+func postForm(ctx context.Context, cfg *config.CmdConfig, endpoint string, form url.Values) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", openIDConnectURL(cfg, endpoint), strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpClient, err := ssoHTTPClient(cfg)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, body, err
+}
+
+// oauthError converts an error response of the SSO server to an error
+func oauthError(statusCode int, body []byte, action string) error {
+	oauthErr := &OAuthError{}
+	if err := json.Unmarshal(body, oauthErr); err != nil || oauthErr.Code == "" {
+		return errors.Errorf("could not %s: HTTP %d", action, statusCode)
+	}
+	return oauthErr
+}
+
+// requestToken posts form to the token endpoint and decodes the issued token
+func requestToken(ctx context.Context, cfg *config.CmdConfig, form url.Values) (*Token, error) {
+	status, body, err := postForm(ctx, cfg, "token", form)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, oauthError(status, body, "get token")
+	}
+	var tok Token
+	if err := json.Unmarshal(body, &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// pkceChallenge derives the S256 code challenge from the code verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// BrowserLoginOptions configures LoginWithBrowser
+type BrowserLoginOptions struct {
+	// Port of the loopback redirect listener, a random port is used when it is 0
+	Port int
+	// OpenURL is called with the authorization URL, it usually opens a web browser
+	OpenURL func(authURL string) error
+}
+
+// LoginWithBrowser runs the OAuth authorization code flow with PKCE: the
+// user logs in with a web browser, which redirects the authorization code
+// to a listener on the loopback interface.
+func LoginWithBrowser(ctx context.Context, cfg *config.CmdConfig, opts BrowserLoginOptions) (*Token, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+
+	type result struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("login failed: state mismatch")
+		case q.Get("error") != "":
+			res.err = &OAuthError{Code: q.Get("error"), Description: q.Get("error_description")}
+		case q.Get("code") == "":
+			res.err = errors.New("login failed: no authorization code received")
+		default:
+			res.code = q.Get("code")
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "%s\n", res.err)
+		} else {
+			fmt.Fprintln(w, "Authentication complete, you may close this window and return to kaectl.")
+		}
+		select {
+		case resultCh <- res:
+		default:
+		}
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	authURL := openIDConnectURL(cfg, "auth") + "?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {cfg.SSOClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid"},
+		"state":                 {state},
+		"code_challenge":        {pkceChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+	if err := opts.OpenURL(authURL); err != nil {
+		return nil, err
+	}
+
+	var res result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-resultCh:
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return requestToken(ctx, cfg, url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {cfg.SSOClientID},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+// Logout ends the SSO session of the refresh token, so that neither it nor
+// the access tokens issued with it can be used anymore.
+func Logout(ctx context.Context, cfg *config.CmdConfig, refreshToken string) error {
+	form := url.Values{
+		"client_id":     {cfg.SSOClientID},
+		"refresh_token": {refreshToken},
+	}
+	status, body, err := postForm(ctx, cfg, "logout", form)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return oauthError(status, body, "logout")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestLoginWithBrowser(t *testing.T) {
+	srv, cfg := newFakeSSOServer(t, []interface{}{
+		&Token{AccessToken: "ACCESS", RefreshToken: "REFRESH", ExpiresIn: 300},
+	})
+	defer srv.Close()
+
+	tok, err := LoginWithBrowser(context.Background(), cfg, BrowserLoginOptions{
+		// the browser follows the redirect of the SSO server to the callback
+		OpenURL: func(authURL string) error {
+			resp, err := http.Get(authURL)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := ioutil.ReadAll(resp.Body)
+				t.Errorf("callback replied %d: %s", resp.StatusCode, body)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tok.AccessToken != "ACCESS" || tok.RefreshToken != "REFRESH" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+}
+
+func TestLoginWithBrowser_stateMismatch(t *testing.T) {
+	// no token must be requested
+	srv, cfg := newFakeSSOServer(t, nil)
+	defer srv.Close()
+
+	_, err := LoginWithBrowser(context.Background(), cfg, BrowserLoginOptions{
+		// a forged redirect to the callback with another state
+		OpenURL: func(authURL string) error {
+			u, err := url.Parse(authURL)
+			if err != nil {
+				return err
+			}
+			callback := u.Query().Get("redirect_uri") + "?" + url.Values{"code": {"CODE"}, "state": {"FORGED"}}.Encode()
+			resp, err := http.Get(callback)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("callback replied %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+			return nil
+		},
+	})
+	if err == nil || err.Error() != "login failed: state mismatch" {
+		t.Errorf("expected state mismatch error, got %v", err)
+	}
+}
+
+func Test_pkceChallenge(t *testing.T) {
+	// BASE64URL(SHA256(verifier)) without padding
+	got := pkceChallenge("dBjftJeZ4CVP-mgrNbUI6x4yJyY26NZHM-ui4oh5ebc")
+	if want := "gF7TozDXsrgiDMCptvm4OtZcExHX0PJv31VqwP7SllE"; got != want {
+		t.Errorf("pkceChallenge() = %q, want %q", got, want)
+	}
+}
+
+func Test_randomString(t *testing.T) {
+	a, err := randomString(32)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, _ := randomString(32)
+	// a PKCE verifier must be 43 to 128 characters
+	if len(a) != 43 || a == b {
+		t.Errorf("randomString(32) = %q and %q, want two different strings of 43 characters", a, b)
+	}
+}
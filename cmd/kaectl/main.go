@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/kaecloud/kaectl/api"
@@ -11,7 +12,9 @@ import (
 	"io"
 	"net"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 )
 
 func main() {
@@ -39,26 +42,44 @@ func main() {
 	// 	}
 	// }
 
+	// the first interrupt cancels the running command so it can clean up,
+	// e.g. close the log stream, the second one exits immediately after
+	// closing the saved logs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+		<-signals
+		cmdutil.CloseLogSavers()
+		os.Exit(cmdutil.ExitCancel)
+	}()
+
 	rootCmd.SetArgs(expandedArgs)
 
-	if cmd, err := rootCmd.ExecuteC(); err != nil {
-		printError(stderr, err, cmd, hasDebug)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			os.Exit(cmdutil.ExitCancel)
+		}
 
-		var httpErr api.HTTPError
-		if errors.As(err, &httpErr) && httpErr.StatusCode == 401 {
-			fmt.Println("hint: try authenticating with `gh auth login`")
+		cmd, _, findErr := rootCmd.Find(expandedArgs)
+		if findErr != nil {
+			cmd = rootCmd
 		}
+		printError(stderr, err, cmd, hasDebug)
 
-		os.Exit(1)
+		os.Exit(cmdutil.ExitCode(err))
 	}
 	if root.HasFailed() {
-		os.Exit(1)
+		os.Exit(cmdutil.ExitError)
 	}
 
 }
 
 func printError(out io.Writer, err error, cmd *cobra.Command, debug bool) {
-	if err == cmdutil.SilentError {
+	if errors.Is(err, cmdutil.SilentError) {
 		return
 	}
 
@@ -68,11 +89,14 @@ func printError(out io.Writer, err error, cmd *cobra.Command, debug bool) {
 		if debug {
 			fmt.Fprintln(out, dnsError)
 		}
-		fmt.Fprintln(out, "check your internet connection or githubstatus.com")
+		fmt.Fprintln(out, "check your internet connection and the server URLs in config file")
 		return
 	}
 
 	fmt.Fprintln(out, err)
+	if hint := errorHint(err, cmd); hint != "" {
+		fmt.Fprintf(out, "hint: %s\n", hint)
+	}
 
 	var flagError *cmdutil.FlagError
 	if errors.As(err, &flagError) || strings.HasPrefix(err.Error(), "unknown command ") {
@@ -83,6 +107,38 @@ func printError(out io.Writer, err error, cmd *cobra.Command, debug bool) {
 	}
 }
 
+// errorHint returns a suggestion to fix the error of an API request made by
+// cmd, or an empty string when there is none
+func errorHint(err error, cmd *cobra.Command) string {
+	resource := "resource"
+	if cmd.Parent() != nil && cmd.Parent().Parent() != nil {
+		resource = cmd.Parent().Name()
+	}
+
+	switch {
+	case errors.As(err, &api.UnauthorizedError{}):
+		return "try authenticating with `kaectl auth login`"
+	case errors.As(err, &api.ForbiddenError{}):
+		return "you don't have the permission, run `kaectl auth status` to check the user and roles you use"
+	case errors.As(err, &api.NotFoundError{}):
+		if resource == "job" || resource == "app" {
+			return fmt.Sprintf("%s not found, run `kaectl %s list` to see the %ss you can access", resource, resource, resource)
+		}
+		return "check the server URLs in config file with `kaectl config view`"
+	case errors.As(err, &api.ConflictError{}):
+		switch cmd.CommandPath() {
+		case "kaectl job create":
+			return "job already exists, use --replace to delete it first"
+		case "kaectl app create":
+			return "app already exists, use `kaectl app deploy` to deploy it"
+		}
+		return fmt.Sprintf("%s already exists", resource)
+	case errors.As(err, &api.ServerError{}):
+		return "the KAE server failed, retry later or run with DEBUG=api for details"
+	}
+	return ""
+}
+
 func isCompletionCommand() bool {
 	return len(os.Args) > 1 && os.Args[1] == "completion"
 }
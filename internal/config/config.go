@@ -2,40 +2,103 @@ package config
 
 import (
 	"encoding/json"
-	"github.com/ghodss/yaml"
-	"github.com/kaecloud/kaectl/utils"
-	"io/ioutil"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 )
 
+const DefaultConfigFile = "~/.kae/config.yaml"
+
+// EnvConfigFile is the environment variable pointing to an alternative
+// config file, the --config flag takes precedence over it.
+const EnvConfigFile = "KAE_CONFIG"
+
+// Environment variables overriding the SSO client in config file, CI uses
+// them to authenticate as the service account of a confidential client.
+// They are aliases of KAE_SSO_CLIENT_ID and KAE_SSO_CLIENT_SECRET.
+const (
+	EnvClientID     = "KAE_CLIENT_ID"
+	EnvClientSecret = "KAE_CLIENT_SECRET"
+)
+
 type CmdConfig struct {
 	SSOHost      string `json:"sso_host" yaml:"sso_host"`
 	SSOUsername  string `json:"sso_username" yaml:"sso_username"`
 	SSOPassword  string `json:"sso_password" yaml:"sso_password"`
 	SSORealm     string `json:"sso_realm" yaml:"sso_realm"`
 	SSOClientID  string `json:"sso_client_id" yaml:"sso_client_id"`
+	SSOClientSecret string `json:"sso_client_secret" yaml:"sso_client_secret"`
 	JobServerUrl string `json:"job_server_url" yaml:"job_server_url"`
 	AppServerUrl string `json:"app_server_url" yaml:"app_server_url"`
 	JobDefaultCluster string `json:"job_default_cluster" yaml:"job_default_cluster"`
+	AppDefaultCluster string `json:"app_default_cluster" yaml:"app_default_cluster"`
+	CAFile string `json:"ca_file" yaml:"ca_file"`
+	ClientCert string `json:"client_cert" yaml:"client_cert"`
+	ClientKey string `json:"client_key" yaml:"client_key"`
+	InsecureSkipVerify Bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	ProxyURL string `json:"proxy_url" yaml:"proxy_url"`
+}
+
+// Bool is a boolean setting, it is written either as a bool or as a string
+// like "true" in config file. It is kept as a string so that an empty value
+// means unset and doesn't override the value of another origin.
+type Bool string
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*b = ""
+	case bool:
+		*b = Bool(strconv.FormatBool(v))
+	case string:
+		*b = Bool(v)
+	default:
+		return fmt.Errorf("invalid value %s: must be true or false", data)
+	}
+	return nil
 }
 
-func LoadCmdConfig(opts ...string) (*CmdConfig, error) {
-	var (
-		cfg      CmdConfig
-		filename  = "~/.kae/config.yaml"
-	)
-	if len(opts) == 1 {
-		filename = opts[0]
+// Filename returns the config file to use, flagValue is the value of
+// --config flag.
+func Filename(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
 	}
-	filename = utils.ExpandUser(filename)
-	data, err := ioutil.ReadFile(filename)
+	if v := os.Getenv(EnvConfigFile); v != "" {
+		return v
+	}
+	return DefaultConfigFile
+}
+
+// LoadCmdConfig loads the config of the given context from the config file,
+// the current context of the file is used when context is empty.
+func LoadCmdConfig(filename string, context string) (*CmdConfig, error) {
+	f, err := LoadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	if strings.HasSuffix(filename, ".yml") || strings.HasSuffix(filename, ".yaml") {
-		err = yaml.Unmarshal(data, &cfg)
-	} else {
-		err = json.Unmarshal(data, &cfg)
+	return f.Config(context)
+}
+
+// EnvNames returns the environment variables overriding key, the first one
+// has the highest precedence.
+func EnvNames(key string) []string {
+	names := []string{"KAE_" + strings.ToUpper(key)}
+	switch key {
+	case "sso_client_id":
+		names = append([]string{EnvClientID}, names...)
+	case "sso_client_secret":
+		names = append([]string{EnvClientSecret}, names...)
 	}
-	return &cfg, err
-}
\ No newline at end of file
+	return names
+}
+
+// FlagName returns the name of the global flag overriding key
+func FlagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
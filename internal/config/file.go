@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/pkg/errors"
+)
+
+// File is the content of config file. The settings at top level are shared
+// by all contexts, each context overrides them with its own settings:
+//
+//	sso_host: sso.example.com
+//	current_context: staging
+//	contexts:
+//	  staging:
+//	    job_server_url: https://job.staging.example.com
+//	  production:
+//	    job_server_url: https://job.example.com
+type File struct {
+	CmdConfig
+	CurrentContext string                `json:"current_context,omitempty" yaml:"current_context,omitempty"`
+	Contexts       map[string]*CmdConfig `json:"contexts,omitempty" yaml:"contexts,omitempty"`
+
+	// Filename is the expanded path the file was loaded from
+	Filename string `json:"-" yaml:"-"`
+}
+
+// NotExistError is returned when config file doesn't exist
+type NotExistError struct {
+	Filename string
+}
+
+func (e *NotExistError) Error() string {
+	return fmt.Sprintf("config file %s does not exist, run `kaectl config init` to create it", e.Filename)
+}
+
+func (e *NotExistError) Unwrap() error {
+	return os.ErrNotExist
+}
+
+// ContextNotFoundError is returned when the selected context is not in config file
+type ContextNotFoundError struct {
+	Name     string
+	Filename string
+}
+
+func (e *ContextNotFoundError) Error() string {
+	return fmt.Sprintf("context %q not found in %s", e.Name, e.Filename)
+}
+
+func isYAML(filename string) bool {
+	return strings.HasSuffix(filename, ".yml") || strings.HasSuffix(filename, ".yaml")
+}
+
+func LoadFile(filename string) (*File, error) {
+	filename = utils.ExpandUser(filename)
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, &NotExistError{Filename: filename}
+	}
+	if err != nil {
+		return nil, err
+	}
+	f := File{Filename: filename}
+	if isYAML(filename) {
+		err = yaml.Unmarshal(data, &f)
+	} else {
+		err = json.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", filename)
+	}
+	return &f, nil
+}
+
+// ContextName returns name, or the current context when name is empty.
+// An empty string means the top level settings are used as is.
+func (f *File) ContextName(name string) string {
+	if name != "" {
+		return name
+	}
+	return f.CurrentContext
+}
+
+// ContextNames returns the names of all contexts in alphabetical order
+func (f *File) ContextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config returns the effective config of the context, see Resolve
+func (f *File) Config(name string) (*CmdConfig, error) {
+	cfg, _, err := f.Resolve(name, nil)
+	return cfg, err
+}
+
+// SetValue sets key to value in the given context of the config data read
+// by UpdateFile, key is set at top level when context is empty.
+func SetValue(data map[string]interface{}, context string, key string, value interface{}) error {
+	if context == "" {
+		data[key] = value
+		return nil
+	}
+	contexts, ok := data["contexts"].(map[string]interface{})
+	if !ok {
+		if data["contexts"] != nil {
+			return errors.New("invalid config file: contexts must be a map")
+		}
+		contexts = map[string]interface{}{}
+		data["contexts"] = contexts
+	}
+	ctx, ok := contexts[context].(map[string]interface{})
+	if !ok {
+		if contexts[context] != nil {
+			return errors.Errorf("invalid config file: context %q must be a map", context)
+		}
+		ctx = map[string]interface{}{}
+		contexts[context] = ctx
+	}
+	ctx[key] = value
+	return nil
+}
+
+// UpdateFile reads the config file as a generic map, calls update with it
+// and writes it back in the same format. Keys unknown to kaectl are kept.
+// The file is created if it doesn't exist.
+func UpdateFile(filename string, update func(data map[string]interface{}) error) error {
+	filename = utils.ExpandUser(filename)
+	data := map[string]interface{}{}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(content) > 0 {
+		if isYAML(filename) {
+			err = yaml.Unmarshal(content, &data)
+		} else {
+			err = json.Unmarshal(content, &data)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse %s", filename)
+		}
+	}
+
+	if err := update(data); err != nil {
+		return err
+	}
+
+	if isYAML(filename) {
+		content, err = yaml.Marshal(data)
+	} else {
+		content, err = json.MarshalIndent(data, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	// the file may contain passwords and client secrets
+	return ioutil.WriteFile(filename, content, 0600)
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `
+sso_host: sso.example.com
+sso_realm: kae
+job_server_url: http://127.0.0.1:8080
+current_context: staging
+contexts:
+  staging:
+    job_server_url: https://job.staging.example.com
+    job_default_cluster: staging
+  production:
+    sso_realm: kae-prod
+    job_server_url: https://job.example.com
+`
+
+func writeTestConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "kaectl-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestFile_Config(t *testing.T) {
+	filename := writeTestConfig(t, "config.yaml", testConfig)
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	f, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg, err := f.Config("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.JobServerUrl != "https://job.staging.example.com" || cfg.SSOHost != "sso.example.com" || cfg.JobDefaultCluster != "staging" {
+		t.Errorf("unexpected config of current context: %+v", cfg)
+	}
+
+	cfg, err = f.Config("production")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SSORealm != "kae-prod" || cfg.SSOHost != "sso.example.com" || cfg.JobDefaultCluster != "" {
+		t.Errorf("unexpected config of production context: %+v", cfg)
+	}
+
+	if _, err := f.Config("missing"); err == nil {
+		t.Errorf("expected error for missing context")
+	}
+}
+
+func TestFile_flat(t *testing.T) {
+	filename := writeTestConfig(t, "config.json", `{"sso_host": "sso.example.com", "job_server_url": "http://127.0.0.1:8080"}`)
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	cfg, err := LoadCmdConfig(filename, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SSOHost != "sso.example.com" || cfg.JobServerUrl != "http://127.0.0.1:8080" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	filename := writeTestConfig(t, "config.yaml", testConfig+"unknown_key: kept\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	err := UpdateFile(filename, func(data map[string]interface{}) error {
+		data["current_context"] = "production"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.CurrentContext != "production" || len(f.Contexts) != 2 {
+		t.Errorf("unexpected file after update: %+v", f)
+	}
+	content, _ := ioutil.ReadFile(filename)
+	if !strings.Contains(string(content), "unknown_key: kept") {
+		t.Errorf("expected unknown keys to be kept, got:\n%s", content)
+	}
+}
+
+func TestFile_Resolve(t *testing.T) {
+	filename := writeTestConfig(t, "config.yaml", testConfig)
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	f, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Setenv("KAE_JOB_DEFAULT_CLUSTER", "from-env")
+	os.Setenv("KAE_SSO_REALM", "from-env")
+	os.Setenv(EnvClientID, "ci-client")
+	defer func() {
+		os.Unsetenv("KAE_JOB_DEFAULT_CLUSTER")
+		os.Unsetenv("KAE_SSO_REALM")
+		os.Unsetenv(EnvClientID)
+	}()
+
+	cfg, origins, err := f.Resolve("", &CmdConfig{SSORealm: "from-flag"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string][2]string{
+		"sso_host":            {"sso.example.com", "file:" + filename},
+		"job_server_url":      {"https://job.staging.example.com", "context:staging"},
+		"job_default_cluster": {"from-env", "env:KAE_JOB_DEFAULT_CLUSTER"},
+		"sso_client_id":       {"ci-client", "env:" + EnvClientID},
+		"sso_realm":           {"from-flag", "flag:--sso-realm"},
+	}
+	for key, want := range expected {
+		if value, _ := cfg.Get(key); value != want[0] {
+			t.Errorf("expected %s to be %q, got %q", key, want[0], value)
+		}
+		if origins[key] != want[1] {
+			t.Errorf("expected origin of %s to be %q, got %q", key, want[1], origins[key])
+		}
+	}
+	if _, ok := origins["app_server_url"]; ok {
+		t.Errorf("expected no origin for unset key")
+	}
+}
+
+func TestFile_Config_boolValue(t *testing.T) {
+	filename := writeTestConfig(t, "config.yaml", "job_server_url: http://127.0.0.1:8080\ninsecure_skip_verify: true\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	f, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg, err := f.Config("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.InsecureSkipVerify != "true" {
+		t.Errorf("expected insecure_skip_verify to be %q, got %q", "true", cfg.InsecureSkipVerify)
+	}
+	if value, _ := cfg.Get("insecure_skip_verify"); value != "true" {
+		t.Errorf("expected Get to return %q, got %q", "true", value)
+	}
+
+	if err := cfg.Set("insecure_skip_verify", "false"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *cfg.Ptr("insecure_skip_verify") != "false" {
+		t.Errorf("expected Ptr to point to %q, got %q", "false", *cfg.Ptr("insecure_skip_verify"))
+	}
+	if err := Validate("insecure_skip_verify", "maybe"); err == nil {
+		t.Errorf("expected an error for a value which is not a bool")
+	}
+	if err := Validate("sso_realm", "maybe"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
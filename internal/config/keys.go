@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// secretKeys are redacted when config is displayed
+var secretKeys = map[string]bool{
+	"sso_password":      true,
+	"sso_client_secret": true,
+}
+
+const redacted = "<redacted>"
+
+var descriptions = map[string]string{
+	"sso_host":             "Host of the SSO (Keycloak) server",
+	"sso_username":         "User to log in with password",
+	"sso_password":         "Password to log in with",
+	"sso_realm":            "SSO realm",
+	"sso_client_id":        "SSO client id",
+	"sso_client_secret":    "SSO client secret to log in as the service account of client",
+	"job_server_url":       "URL of the job server",
+	"app_server_url":       "URL of the app server",
+	"job_default_cluster":  "Default cluster of jobs",
+	"app_default_cluster":  "Default cluster of apps",
+	"ca_file":              "PEM file of the CA certificates to trust besides the system ones",
+	"client_cert":          "PEM file of the client certificate presented to the servers",
+	"client_key":           "PEM file of the private key of client_cert",
+	"insecure_skip_verify": "Set to true to skip verifying the certificates of the servers",
+	"proxy_url":            "URL of the proxy for all requests, the default is HTTPS_PROXY",
+}
+
+// Description returns the human readable description of key
+func Description(key string) string {
+	return descriptions[key]
+}
+
+// Keys returns the keys of CmdConfig in the order they are declared
+func Keys() []string {
+	t := reflect.TypeOf(CmdConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, jsonKey(t.Field(i)))
+	}
+	return keys
+}
+
+func jsonKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// IsSecret reports whether the value of key must not be displayed
+func IsSecret(key string) bool {
+	return secretKeys[key]
+}
+
+// Redact hides the value of secret keys
+func Redact(key, value string) string {
+	if IsSecret(key) && value != "" {
+		return redacted
+	}
+	return value
+}
+
+func (c *CmdConfig) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if jsonKey(v.Type().Field(i)) == key {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, errors.Errorf("unknown config key %q, valid keys are: %s", key, strings.Join(Keys(), ", "))
+}
+
+var (
+	stringPtrType = reflect.TypeOf((*string)(nil))
+	boolType      = reflect.TypeOf(Bool(""))
+)
+
+// Get returns the value of key, key is the name used in config file
+func (c *CmdConfig) Get(key string) (string, error) {
+	f, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+	return f.String(), nil
+}
+
+// Ptr returns the pointer to the field of key as a string, the fields of
+// types based on string like Bool included. It panics when key is unknown.
+func (c *CmdConfig) Ptr(key string) *string {
+	f, err := c.field(key)
+	if err != nil {
+		panic(err)
+	}
+	return f.Addr().Convert(stringPtrType).Interface().(*string)
+}
+
+// Set sets the value of key, key is the name used in config file. The value
+// is not checked, see Validate.
+func (c *CmdConfig) Set(key, value string) error {
+	f, err := c.field(key)
+	if err != nil {
+		return err
+	}
+	f.SetString(value)
+	return nil
+}
+
+// Validate checks that key is known and value is valid for it, the value of
+// a Bool key must be empty or parse as a bool.
+func Validate(key, value string) error {
+	f, err := (&CmdConfig{}).field(key)
+	if err != nil {
+		return err
+	}
+	if f.Type() == boolType && value != "" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.Errorf("invalid value %q of %s: must be true or false", value, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import "os"
+
+// Origins tells where the effective value of each config key comes from,
+// e.g. "file:~/.kae/config.yaml", "context:staging", "env:KAE_SSO_HOST",
+// or "flag:--sso-host". Keys without value are not present.
+type Origins map[string]string
+
+// Resolve computes the effective config of the context, see ContextName.
+// Values are taken in the following order, the later one wins:
+// top level settings of the file, settings of the context, environment
+// variables (see EnvNames) and non-empty fields of overrides.
+func (f *File) Resolve(name string, overrides *CmdConfig) (*CmdConfig, Origins, error) {
+	cfg := f.CmdConfig
+	origins := Origins{}
+	for _, key := range Keys() {
+		if v, _ := cfg.Get(key); v != "" {
+			origins[key] = "file:" + f.Filename
+		}
+	}
+
+	if name = f.ContextName(name); name != "" {
+		ctx, ok := f.Contexts[name]
+		if !ok {
+			return nil, nil, &ContextNotFoundError{Name: name, Filename: f.Filename}
+		}
+		if ctx != nil {
+			cfg.override(ctx, origins, "context:"+name)
+		}
+	}
+
+	for _, key := range Keys() {
+		for _, env := range EnvNames(key) {
+			if v := os.Getenv(env); v != "" {
+				_ = cfg.Set(key, v)
+				origins[key] = "env:" + env
+				break
+			}
+		}
+	}
+
+	if overrides != nil {
+		for _, key := range Keys() {
+			if v, _ := overrides.Get(key); v != "" {
+				_ = cfg.Set(key, v)
+				origins[key] = "flag:--" + FlagName(key)
+			}
+		}
+	}
+	return &cfg, origins, nil
+}
+
+// override sets the non-empty fields of other to c, and records origin
+func (c *CmdConfig) override(other *CmdConfig, origins Origins, origin string) {
+	for _, key := range Keys() {
+		if v, _ := other.Get(key); v != "" {
+			_ = c.Set(key, v)
+			origins[key] = origin
+		}
+	}
+}
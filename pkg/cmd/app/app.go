@@ -1 +1,47 @@
 package app
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	appCreateCmd "github.com/kaecloud/kaectl/pkg/cmd/app/create"
+	appDeleteCmd "github.com/kaecloud/kaectl/pkg/cmd/app/delete"
+	appDeployCmd "github.com/kaecloud/kaectl/pkg/cmd/app/deploy"
+	appGetCmd "github.com/kaecloud/kaectl/pkg/cmd/app/get"
+	appListCmd "github.com/kaecloud/kaectl/pkg/cmd/app/list"
+	appReleasesCmd "github.com/kaecloud/kaectl/pkg/cmd/app/releases"
+	appRollbackCmd "github.com/kaecloud/kaectl/pkg/cmd/app/rollback"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdApp(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "app <command>",
+		Short: "Create, Get, List, Deploy, Roll back, and Delete app",
+		Long:  `Work with apps`,
+		Example: heredoc.Doc(`
+			$ kaectl app create my-app --git git@github.com:me/my-app.git --spec app.yaml
+			$ kaectl app deploy my-app --tag v1.0.0 --cluster mycluster
+			$ kaectl app list
+			$ kaectl app get my-app
+			$ kaectl app releases my-app
+			$ kaectl app rollback my-app --to v0.9.0
+			$ kaectl app delete my-app
+		`),
+		Annotations: map[string]string{
+			"IsCore": "true",
+			"help:arguments": heredoc.Doc(`
+				An app name should be supplied to command
+			`),
+		},
+	}
+
+	cmd.AddCommand(appCreateCmd.NewCmdCreate(f, nil))
+	cmd.AddCommand(appGetCmd.NewCmdGet(f, nil))
+	cmd.AddCommand(appListCmd.NewCmdList(f, nil))
+	cmd.AddCommand(appDeployCmd.NewCmdDeploy(f, nil))
+	cmd.AddCommand(appDeleteCmd.NewCmdDelete(f, nil))
+	cmd.AddCommand(appReleasesCmd.NewCmdReleases(f, nil))
+	cmd.AddCommand(appRollbackCmd.NewCmdRollback(f, nil))
+
+	return cmd
+}
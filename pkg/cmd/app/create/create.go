@@ -0,0 +1,104 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/pkg/spec"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type CreateOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name     string
+	Git      string
+	Comment  string
+	SpecFile string
+	// SpecRequired is set when --spec is given, a missing default spec file
+	// is skipped
+	SpecRequired bool
+}
+
+func NewCmdCreate(f *cmdutil.Factory, runF func(*CreateOptions) error) *cobra.Command {
+	opts := &CreateOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a new app",
+		Long:  `Register a new app in KAE, the app can be deployed with "kaectl app deploy" later.`,
+		Args:  cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ kaectl app create my-app --git git@github.com:me/my-app.git --spec app.yaml
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			opts.SpecRequired = cmd.Flags().Changed("spec")
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return createRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Git, "git", "", "git repository of the app")
+	cmd.Flags().StringVar(&opts.Comment, "comment", "", "comment of the app")
+	cmd.Flags().StringVar(&opts.SpecFile, "spec", "app.yaml", "the spec file")
+
+	return cmd
+}
+
+func createRun(ctx context.Context, opts *CreateOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+
+	obj := &spec.CreateAppArgs{
+		Name:    opts.Name,
+		Git:     opts.Git,
+		Comment: opts.Comment,
+	}
+	if opts.SpecRequired || utils.FileExists(opts.SpecFile) {
+		data, err := ioutil.ReadFile(opts.SpecFile)
+		if err != nil {
+			return err
+		}
+		obj.Spec = string(data)
+	}
+
+	app, err := c.Create(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s\n", utils.Green(fmt.Sprintf("Create app %s successfully", app.Name)))
+	return nil
+}
@@ -0,0 +1,77 @@
+package delete
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type DeleteOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name string
+}
+
+func NewCmdDelete(f *cmdutil.Factory, runF func(*DeleteOptions) error) *cobra.Command {
+	opts := &DeleteOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "delete an app",
+		Long:  `delete app by name.`,
+		Example: heredoc.Doc(`
+			# delete app with specific name
+			$ kaectl app delete my-app
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return deleteRun(cmd.Context(), opts)
+		},
+	}
+
+	return cmd
+}
+
+func deleteRun(ctx context.Context, opts *DeleteOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+	err = c.Delete(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.IO.Out, "%s\n", utils.Green(fmt.Sprintf("Delete app %s successfully", opts.Name)))
+	return nil
+}
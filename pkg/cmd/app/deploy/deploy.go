@@ -0,0 +1,111 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/pkg/spec"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type DeployOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name     string
+	Tag      string
+	Cluster  string
+	SpecFile string
+}
+
+func NewCmdDeploy(f *cmdutil.Factory, runF func(*DeployOptions) error) *cobra.Command {
+	opts := &DeployOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "deploy <name>",
+		Short: "Deploy an app",
+		Long: heredoc.Doc(`
+			Deploy a release of an app to a cluster.
+
+			The spec registered with the app is used unless --spec is given.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ kaectl app deploy my-app --tag v1.0.0 --cluster mycluster
+			$ kaectl app deploy my-app --tag v1.0.1 --spec app.yaml
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if opts.Tag == "" {
+				return &cmdutil.FlagError{Err: errors.New("the value for `--tag` is required")}
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return deployRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Tag, "tag", "t", "", "the release tag to deploy")
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "the cluster to deploy to")
+	cmd.Flags().StringVar(&opts.SpecFile, "spec", "", "the spec file used to override the registered spec")
+
+	return cmd
+}
+
+func deployRun(ctx context.Context, opts *DeployOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	if opts.Cluster == "" {
+		opts.Cluster = cfg.AppDefaultCluster
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+
+	obj := &spec.DeployAppArgs{
+		Tag:     opts.Tag,
+		Cluster: opts.Cluster,
+	}
+	if opts.SpecFile != "" {
+		data, err := ioutil.ReadFile(opts.SpecFile)
+		if err != nil {
+			return err
+		}
+		obj.Spec = string(data)
+	}
+
+	err = c.Deploy(ctx, opts.Name, obj)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s\n", utils.Green(fmt.Sprintf("Deploy app %s:%s to cluster %s successfully", opts.Name, opts.Tag, opts.Cluster)))
+	return nil
+}
@@ -0,0 +1,82 @@
+package get
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type GetOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name string
+}
+
+func NewCmdGet(f *cmdutil.Factory, runF func(*GetOptions) error) *cobra.Command {
+	opts := &GetOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "get an app",
+		Long:  `get app by name.`,
+		Example: heredoc.Doc(`
+			# get app with specific name
+			$ kaectl app get my-app
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return getRun(cmd.Context(), opts)
+		},
+	}
+
+	return cmd
+}
+
+func getRun(ctx context.Context, opts *GetOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+	app, err := c.Get(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+	out := opts.IO.Out
+	fmt.Fprintf(out, "%s:\n  %s\n", utils.Bold("Name"), app.Name)
+	fmt.Fprintf(out, "%s:\n  %s\n", utils.Bold("Git"), app.Git)
+	fmt.Fprintf(out, "%s:\n  %s\n", utils.Bold("Comment"), app.Comment)
+	fmt.Fprintf(out, "%s:\n  %s\n", utils.Bold("Updated"), app.UpdatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(out, "%s:\n  %s\n", utils.Bold("Spec"), app.SpecText)
+	return nil
+}
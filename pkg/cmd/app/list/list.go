@@ -0,0 +1,110 @@
+package list
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type ListOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	JSON bool
+	YAML bool
+}
+
+func NewCmdList(f *cmdutil.Factory, runF func(*ListOptions) error) *cobra.Command {
+	opts := &ListOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List apps",
+		Long:  `List the apps you can access.`,
+		Args:  cobra.NoArgs,
+		Example: heredoc.Doc(`
+			$ kaectl app list
+			$ kaectl app list --json
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML); err != nil {
+				return err
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return listRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "output in JSON format")
+	cmd.Flags().BoolVar(&opts.YAML, "yaml", false, "output in YAML format")
+
+	return cmd
+}
+
+func listRun(ctx context.Context, opts *ListOptions) error {
+	format, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML)
+	if err != nil {
+		return err
+	}
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+	apps, err := c.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format != cmdutil.OutputTable {
+		return cmdutil.WriteStructured(opts.IO.Out, format, apps)
+	}
+
+	tp := utils.NewTablePrinter(opts.IO)
+	if tp.IsTTY() {
+		for _, header := range []string{"NAME", "GIT", "UPDATED", "COMMENT"} {
+			tp.AddField(header, nil, utils.Bold)
+		}
+		tp.EndRow()
+	}
+	now := time.Now()
+	for _, app := range apps {
+		tp.AddField(app.Name, nil, utils.Cyan)
+		tp.AddField(app.Git, nil, nil)
+		if tp.IsTTY() {
+			tp.AddField(utils.FuzzyAgo(now.Sub(app.UpdatedAt)), nil, utils.Gray)
+		} else {
+			tp.AddField(app.UpdatedAt.Format(time.RFC3339), nil, nil)
+		}
+		tp.AddField(app.Comment, nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
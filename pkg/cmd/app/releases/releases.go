@@ -0,0 +1,111 @@
+package releases
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type ReleasesOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name string
+	JSON bool
+	YAML bool
+}
+
+func NewCmdReleases(f *cmdutil.Factory, runF func(*ReleasesOptions) error) *cobra.Command {
+	opts := &ReleasesOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "releases <name>",
+		Short: "List releases of an app",
+		Long:  `List every release of an app, newest first.`,
+		Args:  cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ kaectl app releases my-app
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if _, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML); err != nil {
+				return err
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return releasesRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "output in JSON format")
+	cmd.Flags().BoolVar(&opts.YAML, "yaml", false, "output in YAML format")
+
+	return cmd
+}
+
+func releasesRun(ctx context.Context, opts *ReleasesOptions) error {
+	format, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML)
+	if err != nil {
+		return err
+	}
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+	releases, err := c.Releases(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+
+	if format != cmdutil.OutputTable {
+		return cmdutil.WriteStructured(opts.IO.Out, format, releases)
+	}
+
+	tp := utils.NewTablePrinter(opts.IO)
+	if tp.IsTTY() {
+		for _, header := range []string{"TAG", "IMAGE", "CREATED", "AUTHOR"} {
+			tp.AddField(header, nil, utils.Bold)
+		}
+		tp.EndRow()
+	}
+	now := time.Now()
+	for _, release := range releases {
+		tp.AddField(release.Tag, nil, utils.Cyan)
+		tp.AddField(release.Image, nil, nil)
+		if tp.IsTTY() {
+			tp.AddField(utils.FuzzyAgo(now.Sub(release.CreatedAt)), nil, utils.Gray)
+		} else {
+			tp.AddField(release.CreatedAt.Format(time.RFC3339), nil, nil)
+		}
+		tp.AddField(release.Author, nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
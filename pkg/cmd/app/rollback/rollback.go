@@ -0,0 +1,148 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/pkg/spec"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type RollbackOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name    string
+	To      string
+	Cluster string
+}
+
+func NewCmdRollback(f *cmdutil.Factory, runF func(*RollbackOptions) error) *cobra.Command {
+	opts := &RollbackOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rollback <name>",
+		Short: "Roll back an app to a previous release",
+		Long: heredoc.Doc(`
+			Re-deploy a previous release of an app with the spec it was released with.
+
+			Without --to, the app is rolled back to the release before the one deployed
+			in the cluster now, so rolling back again goes further back.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ kaectl app rollback my-app
+			$ kaectl app rollback my-app --to v1.0.0 --cluster mycluster
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return rollbackRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.To, "to", "", "the tag of the release to roll back to, the default is the release before the deployed one")
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "the cluster to roll back in")
+
+	return cmd
+}
+
+func rollbackRun(ctx context.Context, opts *RollbackOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	if opts.Cluster == "" {
+		opts.Cluster = cfg.AppDefaultCluster
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewAppClient(httpClient, cfg.AppServerUrl, tok)
+	releases, err := c.Releases(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+	var release *api.Release
+	if opts.To != "" {
+		release, err = findRelease(releases, opts.To)
+	} else {
+		var deployment *api.Deployment
+		if deployment, err = c.Deployment(ctx, opts.Name, opts.Cluster); err != nil {
+			return err
+		}
+		release, err = previousRelease(releases, deployment.Tag)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = c.Deploy(ctx, opts.Name, &spec.DeployAppArgs{
+		Tag:     release.Tag,
+		Spec:    release.SpecText,
+		Cluster: opts.Cluster,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(opts.IO.Out, "%s\n", utils.Green(fmt.Sprintf("Roll back app %s to %s in cluster %s successfully", opts.Name, release.Tag, opts.Cluster)))
+	return nil
+}
+
+// findRelease returns the release with the given tag
+func findRelease(releases []*api.Release, tag string) (*api.Release, error) {
+	for _, release := range releases {
+		if release.Tag == tag {
+			return release, nil
+		}
+	}
+	return nil, errors.Errorf("release %s not found", tag)
+}
+
+// previousRelease returns the newest release created before the deployed
+// one, releases must be sorted newest first. The deployed release is looked up
+// by tag, not by position, and its oldest record is used, as deploying a tag
+// again may add a newer release record with that tag.
+func previousRelease(releases []*api.Release, deployedTag string) (*api.Release, error) {
+	if deployedTag == "" {
+		return nil, errors.New("the app is not deployed in the cluster, use `--to` to choose the release")
+	}
+	deployed := -1
+	for i, release := range releases {
+		if release.Tag == deployedTag {
+			deployed = i
+		}
+	}
+	switch {
+	case deployed < 0:
+		return nil, errors.Errorf("the deployed release %s not found, use `--to` to choose the release", deployedTag)
+	case deployed == len(releases)-1:
+		return nil, errors.Errorf("no release before %s to roll back to", deployedTag)
+	}
+	return releases[deployed+1], nil
+}
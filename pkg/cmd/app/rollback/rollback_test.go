@@ -0,0 +1,49 @@
+package rollback
+
+import (
+	"testing"
+
+	"github.com/kaecloud/kaectl/api"
+)
+
+func Test_previousRelease(t *testing.T) {
+	releases := func(tags ...string) []*api.Release {
+		var res []*api.Release
+		for i, tag := range tags {
+			res = append(res, &api.Release{Id: int64(len(tags) - i), Tag: tag})
+		}
+		return res
+	}
+	tests := []struct {
+		name     string
+		releases []*api.Release
+		deployed string
+		wantID   int64
+		wantErr  string
+	}{
+		{name: "newest deployed", releases: releases("v3", "v2", "v1"), deployed: "v3", wantID: 2},
+		{name: "rolled back once", releases: releases("v3", "v2", "v1"), deployed: "v2", wantID: 1},
+		// deploying v2 again added a record newer than v3
+		{name: "redeployed tag", releases: releases("v2", "v3", "v2", "v1"), deployed: "v2", wantID: 1},
+		{name: "oldest deployed", releases: releases("v2", "v1"), deployed: "v1", wantErr: "no release before v1 to roll back to"},
+		{name: "unknown tag", releases: releases("v2", "v1"), deployed: "v9", wantErr: "the deployed release v9 not found, use `--to` to choose the release"},
+		{name: "not deployed", releases: releases("v1"), wantErr: "the app is not deployed in the cluster, use `--to` to choose the release"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := previousRelease(tt.releases, tt.deployed)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Id != tt.wantID {
+				t.Errorf("release = %+v, want id %d", got, tt.wantID)
+			}
+		})
+	}
+}
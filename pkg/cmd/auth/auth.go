@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	authLoginCmd "github.com/kaecloud/kaectl/pkg/cmd/auth/login"
+	authLogoutCmd "github.com/kaecloud/kaectl/pkg/cmd/auth/logout"
+	authStatusCmd "github.com/kaecloud/kaectl/pkg/cmd/auth/status"
+	authWhoamiCmd "github.com/kaecloud/kaectl/pkg/cmd/auth/whoami"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdAuth(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth <command>",
+		Short: "Login, logout, and view authentication status",
+		Long:  `Manage kaectl's authentication state.`,
+		Example: heredoc.Doc(`
+			$ kaectl auth login
+			$ kaectl auth status
+			$ kaectl auth whoami
+			$ kaectl auth logout
+		`),
+	}
+
+	cmdutil.DisableAuthCheck(cmd)
+
+	cmd.AddCommand(authLoginCmd.NewCmdLogin(f, nil))
+	cmd.AddCommand(authLogoutCmd.NewCmdLogout(f, nil))
+	cmd.AddCommand(authStatusCmd.NewCmdStatus(f, nil))
+	cmd.AddCommand(authWhoamiCmd.NewCmdWhoami(f, nil))
+
+	return cmd
+}
@@ -0,0 +1,135 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/auth"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+// loginTimeout is how long we wait for the user to finish the login in browser,
+// the device flow waits until the device code expires instead
+const loginTimeout = 5 * time.Minute
+
+type LoginOptions struct {
+	Config     func() (*config.CmdConfig, error)
+	TokenCache func() (*auth.TokenCache, error)
+	IO         *iostreams.IOStreams
+
+	Port   int
+	Device bool
+}
+
+func NewCmdLogin(f *cmdutil.Factory, runF func(*LoginOptions) error) *cobra.Command {
+	opts := &LoginOptions{
+		IO:         f.IOStreams,
+		Config:     f.Config,
+		TokenCache: f.TokenCache,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Authenticate with KAE",
+		Long: heredoc.Doc(`
+			Authenticate with the SSO server of KAE in a web browser.
+
+			On machines without a browser, use --device to approve the login
+			from another device by entering a one-time code.
+
+			The tokens are stored in ~/.kae/, so sso_password doesn't need to be
+			kept in the config file. When no token is stored, kaectl falls back to
+			logging in with sso_username and sso_password of the config file.
+		`),
+		Args: cobra.NoArgs,
+		Example: heredoc.Doc(`
+			$ kaectl auth login
+			# use a fixed redirect port registered with the SSO client
+			$ kaectl auth login --port 8400
+			# login on a headless machine
+			$ kaectl auth login --device
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Device && cmd.Flags().Changed("port") {
+				return &cmdutil.FlagError{Err: errors.New("`--port` is not supported with `--device`")}
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return loginRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Device, "device", false, "login with the device authorization flow")
+	cmd.Flags().IntVar(&opts.Port, "port", 0, "local port to receive the login redirect, random if not set")
+
+	return cmd
+}
+
+func loginRun(ctx context.Context, opts *LoginOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	cache, err := opts.TokenCache()
+	if err != nil {
+		return err
+	}
+
+	var tok *auth.Token
+	if opts.Device {
+		tok, err = loginWithDevice(ctx, cfg, opts)
+	} else {
+		tok, err = loginWithBrowser(ctx, cfg, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Save(auth.NewCachedToken(cfg, tok)); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.IO.ErrOut, "%s Logged in to %s\n", utils.GreenCheck(), cfg.SSOHost)
+	return nil
+}
+
+func loginWithBrowser(ctx context.Context, cfg *config.CmdConfig, opts *LoginOptions) (*auth.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	errOut := opts.IO.ErrOut
+	return auth.LoginWithBrowser(ctx, cfg, auth.BrowserLoginOptions{
+		Port: opts.Port,
+		OpenURL: func(authURL string) error {
+			fmt.Fprintf(errOut, "Opening %s in your browser.\n", utils.DisplayURL(authURL))
+			if err := utils.OpenInBrowser(authURL); err != nil {
+				fmt.Fprintf(errOut, "%s failed to open the browser, open this URL to continue:\n  %s\n", utils.Yellow("!"), authURL)
+			}
+			return nil
+		},
+	})
+}
+
+func loginWithDevice(ctx context.Context, cfg *config.CmdConfig, opts *LoginOptions) (*auth.Token, error) {
+	errOut := opts.IO.ErrOut
+	return auth.LoginWithDevice(ctx, cfg, auth.DeviceLoginOptions{
+		Prompt: func(code *auth.DeviceCode) error {
+			fmt.Fprintf(errOut, "First copy your one-time code: %s\n", utils.Bold(code.UserCode))
+			fmt.Fprintf(errOut, "Then open %s on any device to approve the login.\n", code.VerificationURI)
+			if code.VerificationURIComplete != "" {
+				fmt.Fprintf(errOut, "Or open %s to skip entering the code.\n", code.VerificationURIComplete)
+			}
+			fmt.Fprintln(errOut, "Waiting for approval...")
+			return nil
+		},
+	})
+}
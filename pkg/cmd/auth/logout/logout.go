@@ -0,0 +1,74 @@
+package logout
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kaecloud/kaectl/auth"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type LogoutOptions struct {
+	Config     func() (*config.CmdConfig, error)
+	TokenCache func() (*auth.TokenCache, error)
+	IO         *iostreams.IOStreams
+}
+
+func NewCmdLogout(f *cmdutil.Factory, runF func(*LogoutOptions) error) *cobra.Command {
+	opts := &LogoutOptions{
+		IO:         f.IOStreams,
+		Config:     f.Config,
+		TokenCache: f.TokenCache,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Log out of KAE",
+		Long:  `Revoke the stored refresh token at the SSO server and remove it from this machine.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return logoutRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func logoutRun(opts *LogoutOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	cache, err := opts.TokenCache()
+	if err != nil {
+		return err
+	}
+
+	errOut := opts.IO.ErrOut
+	cached, err := cache.Load(cfg)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(errOut, "Not logged in to %s\n", cfg.SSOHost)
+		return cache.Clear()
+	}
+	if err == nil && cached.Refreshable() {
+		// the local tokens are removed even when they can't be revoked
+		if err := auth.Logout(context.Background(), cfg, cached.RefreshToken); err != nil {
+			fmt.Fprintf(errOut, "%s failed to revoke the token: %s\n", utils.Yellow("!"), err)
+		}
+	}
+
+	if err := cache.Clear(); err != nil {
+		return err
+	}
+	fmt.Fprintf(errOut, "%s Logged out of %s\n", utils.GreenCheck(), cfg.SSOHost)
+	return nil
+}
@@ -0,0 +1,124 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/auth"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type StatusOptions struct {
+	Config     func() (*config.CmdConfig, error)
+	TokenCache func() (*auth.TokenCache, error)
+	IO         *iostreams.IOStreams
+}
+
+func NewCmdStatus(f *cmdutil.Factory, runF func(*StatusOptions) error) *cobra.Command {
+	opts := &StatusOptions{
+		IO:         f.IOStreams,
+		Config:     f.Config,
+		TokenCache: f.TokenCache,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "View authentication status",
+		Long: heredoc.Doc(`
+			Show the user, realm, roles and expiry of the stored token.
+
+			It exits with status 1 when kaectl is not logged in.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return statusRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func statusRun(opts *StatusOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	cache, err := opts.TokenCache()
+	if err != nil {
+		return err
+	}
+
+	out := opts.IO.ErrOut
+	fmt.Fprintf(out, "%s\n", utils.Bold(cfg.SSOHost))
+
+	if envToken := os.Getenv(auth.EnvToken); envToken != "" {
+		claims, err := auth.DecodeClaims(envToken)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "  %s Using the token in %s\n", utils.GreenCheck(), auth.EnvToken)
+		printClaims(out, claims)
+		fmt.Fprintf(out, "  - Access token expires: %s\n", claims.Expiry().Format(time.RFC3339))
+		return nil
+	}
+
+	cached, err := cache.Load(cfg)
+	if os.IsNotExist(err) {
+		if cfg.SSOClientSecret != "" {
+			fmt.Fprintf(out, "  %s Not logged in, kaectl will log in as the service account of client %s\n", utils.Yellow("!"), cfg.SSOClientID)
+			return nil
+		}
+		if cfg.SSOPassword != "" {
+			fmt.Fprintf(out, "  %s Not logged in, kaectl will log in as %s with the password in config file\n", utils.Yellow("!"), cfg.SSOUsername)
+			return nil
+		}
+		fmt.Fprintf(out, "  %s Not logged in, run `kaectl auth login` to authenticate\n", utils.Red("X"))
+		return cmdutil.SilentError
+	}
+	if err != nil {
+		return err
+	}
+	claims, err := auth.DecodeClaims(cached.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	fmt.Fprintf(out, "  %s Logged in\n", utils.GreenCheck())
+	printClaims(out, claims)
+	if cached.Valid() {
+		fmt.Fprintf(out, "  - Access token expires: %s (in %s)\n", cached.ExpiresAt.Format(time.RFC3339), cached.ExpiresAt.Sub(now).Round(time.Second))
+	} else {
+		fmt.Fprintf(out, "  - Access token expires: %s\n", utils.Yellow("expired, it will be refreshed on next use"))
+	}
+	switch {
+	case !cached.Refreshable():
+		fmt.Fprintf(out, "  - Refresh token expires: %s\n", utils.Red("expired, run `kaectl auth login` again"))
+	case cached.RefreshExpiresAt.IsZero():
+		fmt.Fprintf(out, "  - Refresh token expires: never\n")
+	default:
+		fmt.Fprintf(out, "  - Refresh token expires: %s\n", cached.RefreshExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func printClaims(out io.Writer, claims *auth.Claims) {
+	fmt.Fprintf(out, "  - User: %s\n", utils.Bold(claims.Username()))
+	fmt.Fprintf(out, "  - Realm: %s\n", claims.Realm())
+	if claims.Email != "" {
+		fmt.Fprintf(out, "  - Email: %s\n", claims.Email)
+	}
+	fmt.Fprintf(out, "  - Roles: %s\n", strings.Join(claims.Roles(), ", "))
+}
@@ -0,0 +1,55 @@
+package whoami
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/auth"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/spf13/cobra"
+)
+
+type WhoamiOptions struct {
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+}
+
+func NewCmdWhoami(f *cmdutil.Factory, runF func(*WhoamiOptions) error) *cobra.Command {
+	opts := &WhoamiOptions{
+		IO:          f.IOStreams,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Print the user kaectl is authenticated as",
+		Args:  cobra.NoArgs,
+		Example: heredoc.Doc(`
+			$ kaectl auth whoami
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return whoamiRun(cmd.Context(), opts)
+		},
+	}
+
+	return cmd
+}
+
+func whoamiRun(ctx context.Context, opts *WhoamiOptions) error {
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	claims, err := auth.DecodeClaims(tok)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(opts.IO.Out, claims.Username())
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	configGetCmd "github.com/kaecloud/kaectl/pkg/cmd/config/get"
+	configGetContextsCmd "github.com/kaecloud/kaectl/pkg/cmd/config/getcontexts"
+	configInitCmd "github.com/kaecloud/kaectl/pkg/cmd/config/initialize"
+	configSetCmd "github.com/kaecloud/kaectl/pkg/cmd/config/set"
+	configUseContextCmd "github.com/kaecloud/kaectl/pkg/cmd/config/usecontext"
+	configViewCmd "github.com/kaecloud/kaectl/pkg/cmd/config/view"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdConfig(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config <command>",
+		Short: "Manage configuration for kaectl",
+		Long: heredoc.Doc(`
+			Manage the config file ~/.kae/config.yaml.
+
+			The config file can hold multiple contexts, each of them points to a
+			KAE installation with its own SSO settings, server URLs and default
+			clusters. The --context flag selects a context for a single command.
+		`),
+		Example: heredoc.Doc(`
+			$ kaectl config init
+			$ kaectl config view
+			$ kaectl config get job_server_url
+			$ kaectl config set job_default_cluster mycluster
+			$ kaectl config get-contexts
+			$ kaectl config use-context production
+		`),
+	}
+
+	cmdutil.DisableAuthCheck(cmd)
+
+	cmd.AddCommand(configInitCmd.NewCmdInit(f, nil))
+	cmd.AddCommand(configViewCmd.NewCmdView(f, nil))
+	cmd.AddCommand(configGetCmd.NewCmdGet(f, nil))
+	cmd.AddCommand(configSetCmd.NewCmdSet(f, nil))
+	cmd.AddCommand(configGetContextsCmd.NewCmdGetContexts(f, nil))
+	cmd.AddCommand(configUseContextCmd.NewCmdUseContext(f, nil))
+
+	return cmd
+}
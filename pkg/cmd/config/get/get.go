@@ -0,0 +1,73 @@
+package get
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/spf13/cobra"
+)
+
+type GetOptions struct {
+	Config func() (*config.CmdConfig, error)
+	IO     *iostreams.IOStreams
+
+	Key         string
+	ShowSecrets bool
+}
+
+func NewCmdGet(f *cmdutil.Factory, runF func(*GetOptions) error) *cobra.Command {
+	opts := &GetOptions{
+		IO:     f.IOStreams,
+		Config: f.Config,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a configuration key",
+		Long: heredoc.Doc(`
+			Print the value of a configuration key.
+
+			The values of secret keys like sso_password are redacted unless
+			--show-secrets is given.
+		`),
+		Args: cobra.ExactArgs(1),
+		Example: heredoc.Doc(`
+			$ kaectl config get job_server_url
+
+			# pass the client secret to another tool
+			$ kaectl config get sso_client_secret --show-secrets
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Key = args[0]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return getRun(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.ShowSecrets, "show-secrets", false, "print the values of secret keys in clear")
+
+	return cmd
+}
+
+func getRun(opts *GetOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	value, err := cfg.Get(opts.Key)
+	if err != nil {
+		return err
+	}
+	if !opts.ShowSecrets {
+		value = config.Redact(opts.Key, value)
+	}
+	fmt.Fprintln(opts.IO.Out, value)
+	return nil
+}
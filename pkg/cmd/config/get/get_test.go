@@ -0,0 +1,39 @@
+package get
+
+import (
+	"testing"
+
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+)
+
+func Test_getRun(t *testing.T) {
+	cfg := &config.CmdConfig{JobServerUrl: "https://job.example.com", SSOPassword: "s3cret"}
+	tests := []struct {
+		key         string
+		showSecrets bool
+		want        string
+	}{
+		{key: "job_server_url", want: "https://job.example.com\n"},
+		{key: "sso_password", want: "<redacted>\n"},
+		{key: "sso_password", showSecrets: true, want: "s3cret\n"},
+		{key: "sso_client_secret", want: "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			io, _, out, _ := iostreams.Test()
+			opts := &GetOptions{
+				Config:      func() (*config.CmdConfig, error) { return cfg, nil },
+				IO:          io,
+				Key:         tt.key,
+				ShowSecrets: tt.showSecrets,
+			}
+			if err := getRun(opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package getcontexts
+
+import (
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type GetContextsOptions struct {
+	ConfigFile func() (*config.File, error)
+	IO         *iostreams.IOStreams
+}
+
+func NewCmdGetContexts(f *cmdutil.Factory, runF func(*GetContextsOptions) error) *cobra.Command {
+	opts := &GetContextsOptions{
+		IO:         f.IOStreams,
+		ConfigFile: f.ConfigFile,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List the contexts in config file",
+		Long:  `List the contexts in config file, the current context is marked with "*".`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return getContextsRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func getContextsRun(opts *GetContextsOptions) error {
+	file, err := opts.ConfigFile()
+	if err != nil {
+		return err
+	}
+
+	tp := utils.NewTablePrinter(opts.IO)
+	if tp.IsTTY() {
+		for _, header := range []string{"CURRENT", "NAME", "SSO HOST", "JOB SERVER", "APP SERVER"} {
+			tp.AddField(header, nil, utils.Bold)
+		}
+		tp.EndRow()
+	}
+	for _, name := range file.ContextNames() {
+		cfg, err := file.Config(name)
+		if err != nil {
+			return err
+		}
+		current := ""
+		if name == file.CurrentContext {
+			current = "*"
+		}
+		tp.AddField(current, nil, nil)
+		tp.AddField(name, nil, utils.Cyan)
+		tp.AddField(cfg.SSOHost, nil, nil)
+		tp.AddField(cfg.JobServerUrl, nil, nil)
+		tp.AddField(cfg.AppServerUrl, nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
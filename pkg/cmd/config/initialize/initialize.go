@@ -0,0 +1,110 @@
+package initialize
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type InitOptions struct {
+	IO *iostreams.IOStreams
+
+	Filename string
+}
+
+// question is a config key asked by init, with its default answer
+type question struct {
+	Key     string
+	Prompt  string
+	Default string
+}
+
+var questions = []question{
+	{Key: "sso_host", Prompt: "SSO (Keycloak) host"},
+	{Key: "sso_realm", Prompt: "SSO realm", Default: "kae"},
+	{Key: "sso_client_id", Prompt: "SSO client id", Default: "kae-cli"},
+	{Key: "job_server_url", Prompt: "Job server URL"},
+	{Key: "job_default_cluster", Prompt: "Default cluster of jobs"},
+	{Key: "app_server_url", Prompt: "App server URL"},
+	{Key: "app_default_cluster", Prompt: "Default cluster of apps"},
+}
+
+func NewCmdInit(f *cmdutil.Factory, runF func(*InitOptions) error) *cobra.Command {
+	opts := &InitOptions{
+		IO: f.IOStreams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the config file interactively",
+		Long: heredoc.Doc(`
+			Create the config file by answering a few questions.
+
+			The SSO password is not asked, run "kaectl auth login" afterwards.
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Filename = config.Filename(f.ConfigFilename)
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return initRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func initRun(opts *InitOptions) error {
+	filename := utils.ExpandUser(opts.Filename)
+	if utils.FileExists(filename) {
+		return errors.Errorf("config file %s already exists, use `kaectl config set` to update it", filename)
+	}
+
+	answers := map[string]string{}
+	reader := bufio.NewReader(opts.IO.In)
+	for _, q := range questions {
+		prompt := q.Prompt
+		if q.Default != "" {
+			prompt = fmt.Sprintf("%s (%s)", prompt, q.Default)
+		}
+		fmt.Fprintf(opts.IO.ErrOut, "%s %s: ", utils.Green("?"), utils.Bold(prompt))
+		// at the end of input, the remaining questions get their defaults
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			fmt.Fprintln(opts.IO.ErrOut)
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read answer")
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = q.Default
+		}
+		if answer != "" {
+			answers[q.Key] = answer
+		}
+	}
+
+	err := config.UpdateFile(filename, func(data map[string]interface{}) error {
+		for key, value := range answers {
+			data[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.IO.ErrOut, "%s Created %s, run `kaectl auth login` to authenticate\n", utils.GreenCheck(), filename)
+	return nil
+}
@@ -0,0 +1,83 @@
+package set
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type SetOptions struct {
+	ConfigFile func() (*config.File, error)
+	IO         *iostreams.IOStreams
+
+	Context string
+	Key     string
+	Value   string
+}
+
+func NewCmdSet(f *cmdutil.Factory, runF func(*SetOptions) error) *cobra.Command {
+	opts := &SetOptions{
+		IO:         f.IOStreams,
+		ConfigFile: f.ConfigFile,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Update a configuration key",
+		Long: heredoc.Doc(`
+			Update a configuration key in config file.
+
+			The key is set in the context selected by --context or the current
+			context, and at top level when there is no context.
+		`),
+		Args: cobra.ExactArgs(2),
+		Example: heredoc.Doc(`
+			$ kaectl config set job_default_cluster mycluster
+			$ kaectl config set --context production job_server_url https://job.example.com
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Context = f.Context
+			opts.Key = args[0]
+			opts.Value = args[1]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return setRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func setRun(opts *SetOptions) error {
+	file, err := opts.ConfigFile()
+	if err != nil {
+		return err
+	}
+	// validate the key before touching the file
+	if err := config.Validate(opts.Key, opts.Value); err != nil {
+		return err
+	}
+
+	context := file.ContextName(opts.Context)
+	err = config.UpdateFile(file.Filename, func(data map[string]interface{}) error {
+		return config.SetValue(data, context, opts.Key, opts.Value)
+	})
+	if err != nil {
+		return err
+	}
+
+	where := "at top level"
+	if context != "" {
+		where = fmt.Sprintf("in context %s", context)
+	}
+	fmt.Fprintf(opts.IO.ErrOut, "%s Set %s %s\n", utils.GreenCheck(), opts.Key, where)
+	return nil
+}
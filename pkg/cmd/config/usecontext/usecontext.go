@@ -0,0 +1,62 @@
+package usecontext
+
+import (
+	"fmt"
+
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type UseContextOptions struct {
+	ConfigFile func() (*config.File, error)
+	IO         *iostreams.IOStreams
+
+	Name string
+}
+
+func NewCmdUseContext(f *cmdutil.Factory, runF func(*UseContextOptions) error) *cobra.Command {
+	opts := &UseContextOptions{
+		IO:         f.IOStreams,
+		ConfigFile: f.ConfigFile,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "use-context <name>",
+		Short: "Set the current context in config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return useContextRun(opts)
+		},
+	}
+
+	return cmd
+}
+
+func useContextRun(opts *UseContextOptions) error {
+	file, err := opts.ConfigFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Contexts[opts.Name]; !ok {
+		return &config.ContextNotFoundError{Name: opts.Name, Filename: file.Filename}
+	}
+
+	err = config.UpdateFile(file.Filename, func(data map[string]interface{}) error {
+		data["current_context"] = opts.Name
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.IO.ErrOut, "%s Switched to context %s\n", utils.GreenCheck(), utils.Bold(opts.Name))
+	return nil
+}
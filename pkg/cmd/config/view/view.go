@@ -0,0 +1,98 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type ViewOptions struct {
+	ConfigFile func() (*config.File, error)
+	IO         *iostreams.IOStreams
+
+	Context    string
+	Overrides  *config.CmdConfig
+	ShowOrigin bool
+}
+
+func NewCmdView(f *cmdutil.Factory, runF func(*ViewOptions) error) *cobra.Command {
+	opts := &ViewOptions{
+		IO:         f.IOStreams,
+		ConfigFile: f.ConfigFile,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective configuration",
+		Long: heredoc.Doc(`
+			Print the effective configuration of current context, secrets are redacted.
+
+			With --show-origin, the origin of each value is printed too: the config
+			file, a context, an environment variable or a flag.
+		`),
+		Args: cobra.NoArgs,
+		Example: heredoc.Doc(`
+			$ kaectl config view
+			$ KAE_JOB_SERVER_URL=http://127.0.0.1:8080 kaectl config view --show-origin
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Context = f.Context
+			opts.Overrides = &f.ConfigOverrides
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return viewRun(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.ShowOrigin, "show-origin", false, "show where each value comes from")
+
+	return cmd
+}
+
+func viewRun(opts *ViewOptions) error {
+	file, err := opts.ConfigFile()
+	var notExistErr *config.NotExistError
+	if errors.As(err, &notExistErr) {
+		file = &config.File{Filename: notExistErr.Filename}
+	} else if err != nil {
+		return err
+	}
+	cfg, origins, err := file.Resolve(opts.Context, opts.Overrides)
+	if err != nil {
+		return err
+	}
+	if notExistErr != nil && len(origins) == 0 {
+		return notExistErr
+	}
+
+	if !opts.ShowOrigin {
+		for _, key := range config.Keys() {
+			value, _ := cfg.Get(key)
+			fmt.Fprintf(opts.IO.Out, "%s: %s\n", key, config.Redact(key, value))
+		}
+		return nil
+	}
+
+	tp := utils.NewTablePrinter(opts.IO)
+	for _, key := range config.Keys() {
+		value, _ := cfg.Get(key)
+		origin, ok := origins[key]
+		if !ok {
+			origin = "unset"
+		}
+		tp.AddField(origin, nil, utils.Gray)
+		tp.AddField(key, nil, utils.Bold)
+		tp.AddField(config.Redact(key, value), nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
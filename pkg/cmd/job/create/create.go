@@ -1,6 +1,8 @@
 package create
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/kaecloud/kaectl/api"
@@ -11,11 +13,13 @@ import (
 	"github.com/kaecloud/kaectl/utils"
 	"github.com/spf13/cobra"
 	"io/ioutil"
+	"net/http"
 )
 
 type CreateOptions struct {
+	HttpClient func() (*http.Client, error)
 	Config     func() (*config.CmdConfig, error)
-	AccessToken func() (string, error)
+	AccessToken func(context.Context) (string, error)
 	IO         *iostreams.IOStreams
 
 	Name string
@@ -24,11 +28,13 @@ type CreateOptions struct {
 	Shell bool
 	SpecFile string
 	Cluster string
+	Replace bool
 }
 
 func NewCmdCreate(f *cmdutil.Factory, runF func(*CreateOptions) error) *cobra.Command{
 	opts := &CreateOptions{
 		IO:         f.IOStreams,
+		HttpClient: f.HttpClient,
 		Config:     f.Config,
 		AccessToken: f.GetAccessToken,
 	}
@@ -41,6 +47,9 @@ func NewCmdCreate(f *cmdutil.Factory, runF func(*CreateOptions) error) *cobra.Co
 	 	Example: heredoc.Doc(`
 	 		# create a job with a specific name
 	 		$ kaectl job create my-job --image ubuntu:18.04 --command "echo hello world" --cluster mycluster
+
+	 		# replace the job created from job.yaml
+	 		$ kaectl job create --replace
 	   `),
 	 	Annotations: map[string]string{
 	 		"help:arguments": heredoc.Doc(
@@ -56,7 +65,7 @@ func NewCmdCreate(f *cmdutil.Factory, runF func(*CreateOptions) error) *cobra.Co
 	 			return runF(opts)
 	 		}
 
-	 		return createRun(opts)
+	 		return createRun(cmd.Context(), opts)
 	 	},
 	}
 
@@ -66,20 +75,25 @@ func NewCmdCreate(f *cmdutil.Factory, runF func(*CreateOptions) error) *cobra.Co
 	cmd.Flags().BoolVar(&opts.Shell, "shell", true, "Use shell to run the command")
 	cmd.Flags().StringVar(&opts.SpecFile, "spec", "job.yaml", "the spec file")
 	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "cluster name")
+	cmd.Flags().BoolVar(&opts.Replace, "replace", false, "delete the job with the same name first if it exists")
 
 	return cmd
 }
 
-func createRun(opts *CreateOptions) error {
+func createRun(ctx context.Context, opts *CreateOptions) error {
 	cfg, err := opts.Config()
 	if err != nil {
 		return err
 	}
-	tok, err := opts.AccessToken()
+	tok, err := opts.AccessToken(ctx)
 	if err != nil {
 		return err
 	}
-	c := api.NewJobClient(cfg.JobServerUrl, tok)
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
 
 	var obj *spec.CreateJobArgs
 
@@ -92,7 +106,12 @@ func createRun(opts *CreateOptions) error {
 		if err != nil {
 			return err
 		}
-		err = cmdutil.PrepareJob(sp, c)
+		if opts.Replace {
+			if err := deleteJob(ctx, c, sp.Name); err != nil {
+				return err
+			}
+		}
+		err = cmdutil.PrepareJob(ctx, sp, c)
 		if err != nil {
 			return err
 		}
@@ -101,6 +120,11 @@ func createRun(opts *CreateOptions) error {
 			Cluster: opts.Cluster,
 		}
 	} else {
+		if opts.Replace {
+			if err := deleteJob(ctx, c, opts.Name); err != nil {
+				return err
+			}
+		}
 		obj = &spec.CreateJobArgs{
 			Name: opts.Name,
 			Image: opts.Image,
@@ -110,7 +134,7 @@ func createRun(opts *CreateOptions) error {
 		}
 	}
 
-	job, err := c.Create(obj)
+	job, err := c.Create(ctx, obj)
 	if err != nil {
 		return err
 	}
@@ -118,3 +142,12 @@ func createRun(opts *CreateOptions) error {
 	fmt.Printf("Create job %s successfully\n", job.Name)
 	return nil
 }
+
+// deleteJob deletes the job to be replaced, it is fine when the job doesn't exist
+func deleteJob(ctx context.Context, c *api.JobClient, name string) error {
+	err := c.Delete(ctx, name)
+	if errors.As(err, &api.NotFoundError{}) {
+		return nil
+	}
+	return err
+}
@@ -1,6 +1,7 @@
 package delete
 
 import (
+	"context"
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/kaecloud/kaectl/api"
@@ -9,11 +10,13 @@ import (
 	"github.com/kaecloud/kaectl/pkg/iostreams"
 	"github.com/kaecloud/kaectl/utils"
 	"github.com/spf13/cobra"
+	"net/http"
 )
 
 type DeleteOptions struct {
+	HttpClient func() (*http.Client, error)
 	Config     func() (*config.CmdConfig, error)
-	AccessToken func() (string, error)
+	AccessToken func(context.Context) (string, error)
 	IO         *iostreams.IOStreams
 
 	Name string
@@ -22,6 +25,7 @@ type DeleteOptions struct {
 func NewCmdDelete(f *cmdutil.Factory, runF func(*DeleteOptions) error) *cobra.Command{
 	opts := &DeleteOptions{
 		IO:         f.IOStreams,
+		HttpClient: f.HttpClient,
 		Config:     f.Config,
 		AccessToken: f.GetAccessToken,
 	}
@@ -44,24 +48,28 @@ func NewCmdDelete(f *cmdutil.Factory, runF func(*DeleteOptions) error) *cobra.Co
 				return runF(opts)
 			}
 
-			return deleteRun(opts)
+			return deleteRun(cmd.Context(), opts)
 		},
 	}
 
 	return cmd
 }
 
-func deleteRun(opts *DeleteOptions) error {
+func deleteRun(ctx context.Context, opts *DeleteOptions) error {
 	cfg, err := opts.Config()
 	if err != nil {
 		return err
 	}
-	tok, err := opts.AccessToken()
+	tok, err := opts.AccessToken(ctx)
 	if err != nil {
 		return err
 	}
-	c := api.NewJobClient(cfg.JobServerUrl, tok)
-	err = c.Delete(opts.Name)
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+	err = c.Delete(ctx, opts.Name)
 	if err != nil {
 		return err
 	}
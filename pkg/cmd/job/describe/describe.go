@@ -0,0 +1,246 @@
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type DescribeOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name    string
+	Cluster string
+	JSON    bool
+	YAML    bool
+}
+
+func NewCmdDescribe(f *cmdutil.Factory, runF func(*DescribeOptions) error) *cobra.Command {
+	opts := &DescribeOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "describe <name>",
+		Short: "Show the status, pods and events of a job",
+		Long: heredoc.Doc(`
+			Show the status of a job, its start and completion times, the number of
+			succeeded and failed pods, every pod and the recent Kubernetes events.
+		`),
+		Example: heredoc.Doc(`
+	 		# describe a job
+	 		$ kaectl job describe my-job
+
+	 		# get the exit codes of the pods in a script
+	 		$ kaectl job describe my-job --json | jq '.pods[].exit_code'
+	   `),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if _, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML); err != nil {
+				return err
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return describeRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Cluster, "cluster", "c", "", "cluster of the job, the default is the cluster the job was created in")
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "output in JSON format")
+	cmd.Flags().BoolVar(&opts.YAML, "yaml", false, "output in YAML format")
+
+	return cmd
+}
+
+// jobDescription is everything shown by describe
+type jobDescription struct {
+	Job    *api.Job       `json:"job"`
+	Status *api.JobStatus `json:"status"`
+	Pods   []*api.Pod     `json:"pods"`
+	Events []*api.Event   `json:"events"`
+}
+
+func describeRun(ctx context.Context, opts *DescribeOptions) error {
+	format, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML)
+	if err != nil {
+		return err
+	}
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+
+	d := &jobDescription{}
+	if d.Job, err = c.Get(ctx, opts.Name); err != nil {
+		return err
+	}
+	cluster := opts.Cluster
+	if cluster == "" {
+		cluster = d.Job.Cluster
+	}
+	if d.Status, err = c.Status(ctx, opts.Name, cluster); err != nil {
+		return err
+	}
+	if d.Pods, err = c.Pods(ctx, opts.Name, cluster); err != nil {
+		return err
+	}
+	if d.Events, err = c.Events(ctx, opts.Name, cluster); err != nil {
+		return err
+	}
+
+	if format != cmdutil.OutputTable {
+		return cmdutil.WriteStructured(opts.IO.Out, format, d)
+	}
+	return printDescription(opts.IO, d, time.Now())
+}
+
+func printDescription(ios *iostreams.IOStreams, d *jobDescription, now time.Time) error {
+	out := ios.Out
+	status := d.Status
+	field(out, "Name", d.Job.Name)
+	field(out, "Cluster", d.Job.Cluster)
+	field(out, "Comment", d.Job.Comment)
+	field(out, "Created", formatTime(d.Job.CreatedAt, now))
+	if color := cmdutil.JobStatusColorFunc(status.Phase); color != nil {
+		field(out, "Status", color(status.Phase))
+	} else {
+		field(out, "Status", status.Phase)
+	}
+	if status.StartTime != nil {
+		field(out, "Started", formatTime(*status.StartTime, now))
+	}
+	if status.CompletionTime != nil {
+		field(out, "Completed", formatTime(*status.CompletionTime, now))
+	}
+	if status.StartTime != nil {
+		end := now
+		if status.CompletionTime != nil {
+			end = *status.CompletionTime
+		}
+		field(out, "Duration", end.Sub(*status.StartTime).Round(time.Second).String())
+	}
+	field(out, "Pods", fmt.Sprintf("%d active / %d succeeded / %d failed", status.Active, status.Succeeded, status.Failed))
+
+	if len(status.Conditions) > 0 {
+		fmt.Fprintf(out, "\n%s:\n", utils.Bold("Conditions"))
+		tp := utils.NewTablePrinter(ios)
+		header(tp, "TYPE", "STATUS", "REASON", "MESSAGE")
+		for _, cond := range status.Conditions {
+			tp.AddField(cond.Type, nil, nil)
+			tp.AddField(cond.Status, nil, nil)
+			tp.AddField(cond.Reason, nil, nil)
+			tp.AddField(cond.Message, nil, nil)
+			tp.EndRow()
+		}
+		if err := tp.Render(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\n%s:\n", utils.Bold("Pods"))
+	if len(d.Pods) == 0 {
+		fmt.Fprintln(out, "  <none>")
+	} else {
+		tp := utils.NewTablePrinter(ios)
+		header(tp, "NAME", "PHASE", "NODE", "RESTARTS", "EXIT CODE", "REASON", "AGE")
+		for _, pod := range d.Pods {
+			tp.AddField(pod.Name, nil, utils.Cyan)
+			tp.AddField(pod.Phase, nil, cmdutil.JobStatusColorFunc(pod.Phase))
+			tp.AddField(pod.Node, nil, nil)
+			tp.AddField(strconv.Itoa(pod.Restarts), nil, nil)
+			if pod.ExitCode != nil {
+				tp.AddField(strconv.Itoa(*pod.ExitCode), nil, nil)
+			} else {
+				tp.AddField("-", nil, nil)
+			}
+			tp.AddField(pod.Reason, nil, nil)
+			age(tp, pod.StartTime, now)
+			tp.EndRow()
+		}
+		if err := tp.Render(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "\n%s:\n", utils.Bold("Events"))
+	if len(d.Events) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return nil
+	}
+	tp := utils.NewTablePrinter(ios)
+	header(tp, "LAST SEEN", "TYPE", "REASON", "OBJECT", "MESSAGE")
+	for _, ev := range d.Events {
+		age(tp, ev.LastTimestamp, now)
+		if ev.Type == "Warning" {
+			tp.AddField(ev.Type, nil, utils.Yellow)
+		} else {
+			tp.AddField(ev.Type, nil, nil)
+		}
+		tp.AddField(ev.Reason, nil, nil)
+		tp.AddField(ev.Object, nil, nil)
+		tp.AddField(ev.Message, nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
+
+func field(w io.Writer, name, value string) {
+	fmt.Fprintf(w, "%s %s\n", utils.Bold(fmt.Sprintf("%-10s", name+":")), value)
+}
+
+func formatTime(t, now time.Time) string {
+	return fmt.Sprintf("%s (%s)", t.Local().Format(time.RFC3339), utils.FuzzyAgo(now.Sub(t)))
+}
+
+// header adds the header row of a table, it is omitted when the output is
+// not a terminal like in list
+func header(tp utils.TablePrinter, names ...string) {
+	if !tp.IsTTY() {
+		return
+	}
+	for _, name := range names {
+		tp.AddField(name, nil, utils.Bold)
+	}
+	tp.EndRow()
+}
+
+func age(tp utils.TablePrinter, t, now time.Time) {
+	switch {
+	case t.IsZero():
+		tp.AddField("-", nil, nil)
+	case tp.IsTTY():
+		tp.AddField(utils.FuzzyAgo(now.Sub(t)), nil, utils.Gray)
+	default:
+		tp.AddField(t.Format(time.RFC3339), nil, nil)
+	}
+}
@@ -1,6 +1,7 @@
 package get
 
 import (
+	"context"
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/kaecloud/kaectl/api"
@@ -9,11 +10,13 @@ import (
 	"github.com/kaecloud/kaectl/pkg/iostreams"
 	"github.com/kaecloud/kaectl/utils"
 	"github.com/spf13/cobra"
+	"net/http"
 )
 
 type GetOptions struct {
+	HttpClient func() (*http.Client, error)
 	Config     func() (*config.CmdConfig, error)
-	AccessToken func() (string, error)
+	AccessToken func(context.Context) (string, error)
 	IO         *iostreams.IOStreams
 
 	Name string
@@ -22,6 +25,7 @@ type GetOptions struct {
 func NewCmdGet(f *cmdutil.Factory, runF func(*GetOptions) error) *cobra.Command{
 	opts := &GetOptions{
 		IO:         f.IOStreams,
+		HttpClient: f.HttpClient,
 		Config:     f.Config,
 		AccessToken: f.GetAccessToken,
 	}
@@ -48,7 +52,7 @@ func NewCmdGet(f *cmdutil.Factory, runF func(*GetOptions) error) *cobra.Command{
 			// 	return &cmdutil.FlagError{Err: errors.New(`The '--template' option is not supported with '--homepage, --team, --enable-issues or --enable-wiki'`)}
 			// }
 
-			return getRun(opts)
+			return getRun(cmd.Context(), opts)
 		},
 	}
 
@@ -58,17 +62,21 @@ func NewCmdGet(f *cmdutil.Factory, runF func(*GetOptions) error) *cobra.Command{
 	return cmd
 }
 
-func getRun(opts *GetOptions) error {
+func getRun(ctx context.Context, opts *GetOptions) error {
 	cfg, err := opts.Config()
 	if err != nil {
 		return err
 	}
-	tok, err := opts.AccessToken()
+	tok, err := opts.AccessToken(ctx)
 	if err != nil {
 		return err
 	}
-	c := api.NewJobClient(cfg.JobServerUrl, tok)
-	job, err := c.Get(opts.Name)
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+	job, err := c.Get(ctx, opts.Name)
 	if err != nil {
 		return err
 	}
@@ -4,9 +4,12 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	jobCreateCmd "github.com/kaecloud/kaectl/pkg/cmd/job/create"
 	jobGetCmd "github.com/kaecloud/kaectl/pkg/cmd/job/get"
+	jobListCmd "github.com/kaecloud/kaectl/pkg/cmd/job/list"
 	jobDeleteCmd "github.com/kaecloud/kaectl/pkg/cmd/job/delete"
+	jobDescribeCmd "github.com/kaecloud/kaectl/pkg/cmd/job/describe"
 	jobRunCmd "github.com/kaecloud/kaectl/pkg/cmd/job/run"
 	jobLogsCmd "github.com/kaecloud/kaectl/pkg/cmd/job/logs"
+	jobWaitCmd "github.com/kaecloud/kaectl/pkg/cmd/job/wait"
 	"github.com/kaecloud/kaectl/pkg/cmdutil"
 	"github.com/spf13/cobra"
 )
@@ -19,7 +22,10 @@ func NewCmdJob(f *cmdutil.Factory) *cobra.Command {
 		Example: heredoc.Doc(`
 			$ kaectl job create my-job --image ubuntu:16.04 --command "echo hello" --cluster mycluster
 			$ kaectl job get my-job
+			$ kaectl job describe my-job
+			$ kaectl job list --cluster mycluster
 			$ kaectl job delete my-job
+			$ kaectl job wait my-job --for=complete --timeout 1h
 		`),
 		Annotations: map[string]string{
 			"IsCore": "true",
@@ -32,9 +38,12 @@ func NewCmdJob(f *cmdutil.Factory) *cobra.Command {
 
 	cmd.AddCommand(jobCreateCmd.NewCmdCreate(f, nil))
 	cmd.AddCommand(jobGetCmd.NewCmdGet(f, nil))
+	cmd.AddCommand(jobDescribeCmd.NewCmdDescribe(f, nil))
+	cmd.AddCommand(jobListCmd.NewCmdList(f, nil))
 	cmd.AddCommand(jobDeleteCmd.NewCmdDelete(f, nil))
 	cmd.AddCommand(jobRunCmd.NewCmdRun(f, nil))
 	cmd.AddCommand(jobLogsCmd.NewCmdLogs(f, nil))
+	cmd.AddCommand(jobWaitCmd.NewCmdWait(f, nil))
 
 	return cmd
 }
\ No newline at end of file
@@ -0,0 +1,148 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type ListOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Cluster    string
+	NamePrefix string
+	Limit      int
+	JSON       bool
+	YAML       bool
+}
+
+func NewCmdList(f *cmdutil.Factory, runF func(*ListOptions) error) *cobra.Command {
+	opts := &ListOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List jobs",
+		Long:  `List the jobs you can access, newest first.`,
+		Args:  cobra.NoArgs,
+		Example: heredoc.Doc(`
+			# list the 20 newest jobs in a cluster
+			$ kaectl job list --cluster mycluster --limit 20
+
+			# list jobs created by "kaectl job run" for a spec named train
+			$ kaectl job list --name-prefix train-
+
+			# dump all jobs as JSON
+			$ kaectl job list --json
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Limit < 0 {
+				return &cmdutil.FlagError{Err: errors.New("invalid value for `--limit`: must not be negative")}
+			}
+			if _, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML); err != nil {
+				return err
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return listRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "only list jobs in this cluster")
+	cmd.Flags().StringVar(&opts.NamePrefix, "name-prefix", "", "only list jobs whose name starts with this prefix")
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "L", 30, "maximum number of jobs to list, 0 means no limit")
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "output in JSON format")
+	cmd.Flags().BoolVar(&opts.YAML, "yaml", false, "output in YAML format")
+
+	return cmd
+}
+
+func listRun(ctx context.Context, opts *ListOptions) error {
+	format, err := cmdutil.NewOutputFormat(opts.JSON, opts.YAML)
+	if err != nil {
+		return err
+	}
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+	jobs, err := c.List(ctx, opts.Cluster)
+	if err != nil {
+		return err
+	}
+	jobs = filterJobs(jobs, opts.NamePrefix, opts.Limit)
+
+	if format != cmdutil.OutputTable {
+		return cmdutil.WriteStructured(opts.IO.Out, format, jobs)
+	}
+
+	tp := utils.NewTablePrinter(opts.IO)
+	if tp.IsTTY() {
+		for _, header := range []string{"NAME", "CLUSTER", "STATUS", "AGE", "COMMENT"} {
+			tp.AddField(header, nil, utils.Bold)
+		}
+		tp.EndRow()
+	}
+	now := time.Now()
+	for _, job := range jobs {
+		tp.AddField(job.Name, nil, utils.Cyan)
+		tp.AddField(job.Cluster, nil, nil)
+		tp.AddField(job.Status, nil, cmdutil.JobStatusColorFunc(job.Status))
+		if tp.IsTTY() {
+			tp.AddField(utils.FuzzyAgo(now.Sub(job.CreatedAt)), nil, utils.Gray)
+		} else {
+			tp.AddField(job.CreatedAt.Format(time.RFC3339), nil, nil)
+		}
+		tp.AddField(job.Comment, nil, nil)
+		tp.EndRow()
+	}
+	return tp.Render()
+}
+
+// filterJobs keeps the jobs whose name starts with prefix, the newest limit
+// jobs are returned when limit is positive.
+func filterJobs(jobs []*api.Job, prefix string, limit int) []*api.Job {
+	res := make([]*api.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if strings.HasPrefix(job.Name, prefix) {
+			res = append(res, job)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].CreatedAt.After(res[j].CreatedAt)
+	})
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
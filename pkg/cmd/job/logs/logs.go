@@ -1,6 +1,8 @@
 package logs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/kaecloud/kaectl/api"
@@ -8,21 +10,42 @@ import (
 	"github.com/kaecloud/kaectl/pkg/cmdutil"
 	"github.com/kaecloud/kaectl/pkg/iostreams"
 	"github.com/spf13/cobra"
+	"net/http"
+	"time"
 )
 
 type LogsOptions struct {
+	HttpClient func() (*http.Client, error)
 	Config     func() (*config.CmdConfig, error)
-	AccessToken func() (string, error)
+	AccessToken func(context.Context) (string, error)
 	IO         *iostreams.IOStreams
 
 	Name string
 	Cluster string
 	Follow bool
+	Pod string
+	AllPods bool
+	Container string
+	Tail int
+	Since time.Duration
+	SinceTime string
+	Timestamps bool
+	Previous bool
+	SaveFile string
+	OutputDir string
+	Gzip bool
+	MaxSize int
+	// Saver is built from SaveFile, OutputDir, Gzip and MaxSize
+	Saver *cmdutil.LogSaver
+	Grep []string
+	Exclude []string
+	Highlight bool
 }
 
 func NewCmdLogs(f *cmdutil.Factory, runF func(*LogsOptions) error) *cobra.Command{
 	opts := &LogsOptions{
 		IO:         f.IOStreams,
+		HttpClient: f.HttpClient,
 		Config:     f.Config,
 		AccessToken: f.GetAccessToken,
 	}
@@ -34,51 +57,185 @@ func NewCmdLogs(f *cmdutil.Factory, runF func(*LogsOptions) error) *cobra.Comman
 		Example: heredoc.Doc(`
 	 		# get job's log
 	 		$ kaectl job logs my-job --follow
+
+	 		# show the last 100 lines of the past hour with timestamps
+	 		$ kaectl job logs my-job --tail 100 --since 1h --timestamps
+
+	 		# follow the logs of all pods of a parallel job
+	 		$ kaectl job logs my-job --all-pods --follow
+
+	 		# keep a compressed copy of the log of every pod, rotated every 100MB
+	 		$ kaectl job logs my-job --all-pods --follow --output-dir ./logs --gzip --max-size 100
+
+	 		# only show the lines with the loss except the debug ones
+	 		$ kaectl job logs my-job --follow --grep 'loss=' --exclude 'DEBUG'
+
+	 		# show why the main container of a pod crashed before restarting
+	 		$ kaectl job logs my-job --pod my-job-x7k2p --container main --previous
 	   `),
 	    Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				opts.Name = args[0]
 			}
+			if opts.AllPods && opts.Pod != "" {
+				return &cmdutil.FlagError{Err: errors.New("specify only one of `--pod` or `--all-pods`")}
+			}
+			if opts.Tail < 0 {
+				return &cmdutil.FlagError{Err: errors.New("invalid value for `--tail`: must not be negative")}
+			}
+			if opts.Since != 0 && opts.SinceTime != "" {
+				return &cmdutil.FlagError{Err: errors.New("specify only one of `--since` or `--since-time`")}
+			}
+			if _, err := parseSinceTime(opts.SinceTime); err != nil {
+				return &cmdutil.FlagError{Err: err}
+			}
+			if _, err := cmdutil.NewLogFilter(opts.Grep, opts.Exclude); err != nil {
+				return err
+			}
+			saver, err := cmdutil.NewLogSaver(opts.SaveFile, opts.OutputDir, opts.Gzip, opts.MaxSize)
+			if err != nil {
+				return err
+			}
+			if saver != nil {
+				saver.Job = opts.Name
+			}
+			opts.Saver = saver
 
 			if runF != nil {
 				return runF(opts)
 			}
 
-			return logsRun(opts)
+			return logsRun(cmd.Context(), opts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&opts.Cluster, "cluster", "c", "", "cluster")
-	cmd.Flags().BoolVar(&opts.Follow, "follow", false, "follow the pod log")
+	cmd.Flags().BoolVar(&opts.Follow, "follow", false, "follow the pod log, reconnect when the connection is lost")
+	cmd.Flags().StringVar(&opts.Pod, "pod", "", "the pod to show the log of, the server chooses one by default")
+	cmd.Flags().BoolVar(&opts.AllPods, "all-pods", false, "show the logs of all pods of the job prefixed by the pod name, pods started later are not included")
+	cmd.Flags().StringVar(&opts.Container, "container", "", "the container to show the log of, required when the pod has more than one container")
+	cmd.Flags().IntVar(&opts.Tail, "tail", 0, "number of recent lines to show, 0 means all lines")
+	cmd.Flags().DurationVar(&opts.Since, "since", 0, "only show the lines newer than a relative duration like 5s, 2m or 3h")
+	cmd.Flags().StringVar(&opts.SinceTime, "since-time", "", "only show the lines after a time in RFC3339 format, e.g. 2020-10-01T12:00:00Z")
+	cmd.Flags().BoolVar(&opts.Timestamps, "timestamps", false, "show the time of every line")
+	cmd.Flags().BoolVar(&opts.Previous, "previous", false, "show the log of the previous terminated container, e.g. the crashed one")
+	cmd.Flags().StringArrayVar(&opts.Grep, "grep", nil, "only show the lines matching a regular expression, can be repeated to show the lines matching any")
+	cmd.Flags().StringArrayVar(&opts.Exclude, "exclude", nil, "hide the lines matching a regular expression, can be repeated")
+	cmd.Flags().BoolVar(&opts.Highlight, "highlight", true, "highlight the matches of --grep and color the error and warning lines")
+	cmd.Flags().StringVar(&opts.SaveFile, "save", "", "also append the log to a file, the lines are not filtered")
+	cmd.Flags().StringVar(&opts.OutputDir, "output-dir", "", "also append the log of every pod to <pod>.log in a directory, the lines are not filtered")
+	cmd.Flags().BoolVar(&opts.Gzip, "gzip", false, "compress the saved log with gzip")
+	cmd.Flags().IntVar(&opts.MaxSize, "max-size", 0, "rotate a saved file when it reaches this size in MB, 0 disables rotation")
 
 	return cmd
 }
 
-func logsRun(opts *LogsOptions) error {
+func logsRun(ctx context.Context, opts *LogsOptions) error {
 	cfg, err := opts.Config()
 	if err != nil {
 		return err
 	}
-	tok, err := opts.AccessToken()
+	tok, err := opts.AccessToken(ctx)
 	if err != nil {
 		return err
 	}
-	c := api.NewJobClient(cfg.JobServerUrl, tok)
-	outCh, err := c.Logs(opts.Name, "", opts.Cluster, opts.Follow)
+	httpClient, err := opts.HttpClient()
 	if err != nil {
 		return err
 	}
-	for {
-		item, ok := <- outCh
-		if !ok {
-			return nil
-		}
-		switch v := item.(type) {
-		case error:
-			return v
-		case string:
-			fmt.Printf("%s\n", v)
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+	sinceTime, err := parseSinceTime(opts.SinceTime)
+	if err != nil {
+		return err
+	}
+	logOpts := api.LogOptions{
+		Cluster:    opts.Cluster,
+		Pod:        opts.Pod,
+		Container:  opts.Container,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+		Since:      opts.Since,
+		SinceTime:  sinceTime,
+		Timestamps: opts.Timestamps,
+		Previous:   opts.Previous,
+	}
+	filter, err := cmdutil.NewLogFilter(opts.Grep, opts.Exclude)
+	if err != nil {
+		return err
+	}
+	printer := &cmdutil.LogPrinter{
+		IO:         opts.IO,
+		Timestamps: opts.Timestamps,
+		PodPrefix:  opts.AllPods,
+		Filter:     filter,
+		Highlight:  opts.Highlight,
+		Saver:      opts.Saver,
+	}
+	if opts.AllPods {
+		return allPodsLogsRun(ctx, c, opts.Name, logOpts, printer)
+	}
+	events, err := c.Logs(ctx, opts.Name, logOpts)
+	if err != nil {
+		return err
+	}
+	return printer.Print(ctx, events)
+}
+
+// allPodsLogsRun prints the merged logs of the pods of job
+func allPodsLogsRun(ctx context.Context, c *api.JobClient, name string, logOpts api.LogOptions, printer *cmdutil.LogPrinter) error {
+	pods, err := c.Pods(ctx, name, logOpts.Cluster)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("job %s has no pods", name)
+	}
+
+	// the streams are opened concurrently, cancel closes the streams opened
+	// and aborts the others when opening one fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type opened struct {
+		pod    string
+		events <-chan api.LogEvent
+		err    error
+	}
+	results := make(chan opened, len(pods))
+	for _, pod := range pods {
+		podOpts := logOpts
+		podOpts.Pod = pod.Name
+		go func() {
+			events, err := c.Logs(ctx, name, podOpts)
+			results <- opened{pod: podOpts.Pod, events: events, err: err}
+		}()
+	}
+	streams := make(map[string]<-chan api.LogEvent, len(pods))
+	var firstErr error
+	for range pods {
+		res := <-results
+		switch {
+		case res.err == nil:
+			streams[res.pod] = res.events
+		case firstErr == nil:
+			// the other streams fail with context.Canceled from now on
+			firstErr = fmt.Errorf("pod %s: %w", res.pod, res.err)
+			cancel()
 		}
 	}
+	if firstErr != nil {
+		return firstErr
+	}
+	return printer.Print(ctx, api.MergeLogStreams(ctx, streams))
+}
+
+func parseSinceTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value for `--since-time`: %q is not in RFC3339 format, e.g. 2020-10-01T12:00:00Z", value)
+	}
+	return t, nil
 }
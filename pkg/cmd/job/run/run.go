@@ -1,6 +1,7 @@
 package run
 
 import (
+	"context"
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
 	"github.com/kaecloud/kaectl/api"
@@ -12,22 +13,32 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"io/ioutil"
+	"net/http"
 	"strings"
 )
 
 type RunOptions struct {
+	HttpClient  func() (*http.Client, error)
 	Config      func() (*config.CmdConfig, error)
-	AccessToken func() (string, error)
+	AccessToken func(context.Context) (string, error)
 	IO          *iostreams.IOStreams
 
 	Command  string
 	SpecFile string
 	Cluster string
+	SaveFile string
+	OutputDir string
+	Gzip bool
+	MaxSize int
+	// Saver is built from SaveFile, OutputDir, Gzip and MaxSize, its Job is
+	// set once the job is created
+	Saver *cmdutil.LogSaver
 }
 
 func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command {
 	opts := &RunOptions{
 		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
 		Config:      f.Config,
 		AccessToken: f.GetAccessToken,
 	}
@@ -35,11 +46,20 @@ func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command
 	cmd := &cobra.Command{
 		Use:   "run <command>",
 		Short: "Run a new job",
-		Long:  `Run a new k8s job.`,
+		Long: heredoc.Doc(`
+			Run a new k8s job and show its log until it finishes.
+
+			Like a local command, kaectl exits with the exit code of the container when
+			the job fails, 137 when it ran out of memory, 143 when its pod was evicted
+			and 124 when it exceeded activeDeadlineSeconds.
+		`),
 		Args:  cobra.ExactArgs(1),
 		Example: heredoc.Doc(`
 	 		# run echo command in k8s
 	 		$ kaectl job run "echo hello world"
+
+	 		# keep a copy of the log in train.log
+	 		$ kaectl job run "python train.py" --save train.log
 	   `),
 		Annotations: map[string]string{
 			"help:arguments": heredoc.Doc(
@@ -48,21 +68,30 @@ func NewCmdRun(f *cmdutil.Factory, runF func(*RunOptions) error) *cobra.Command
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Command = args[0]
+			saver, err := cmdutil.NewLogSaver(opts.SaveFile, opts.OutputDir, opts.Gzip, opts.MaxSize)
+			if err != nil {
+				return err
+			}
+			opts.Saver = saver
 			if runF != nil {
 				return runF(opts)
 			}
 
-			return runRun(opts)
+			return runRun(cmd.Context(), opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.Cluster, "cluster", "", "the cluster used to run command")
 	cmd.Flags().StringVar(&opts.SpecFile, "spec", "job.yaml", "the spec file")
+	cmd.Flags().StringVar(&opts.SaveFile, "save", "", "also append the log to a file")
+	cmd.Flags().StringVar(&opts.OutputDir, "output-dir", "", "also append the log to <job>.log in a directory")
+	cmd.Flags().BoolVar(&opts.Gzip, "gzip", false, "compress the saved log with gzip")
+	cmd.Flags().IntVar(&opts.MaxSize, "max-size", 0, "rotate the saved file when it reaches this size in MB, 0 disables rotation")
 
 	return cmd
 }
 
-func runRun(opts *RunOptions) error {
+func runRun(ctx context.Context, opts *RunOptions) error {
 	// var obj map[string]interface{}
 
 	cfg, err := opts.Config()
@@ -72,11 +101,15 @@ func runRun(opts *RunOptions) error {
 	if opts.Cluster == "" {
 		opts.Cluster = cfg.JobDefaultCluster
 	}
-	tok, err := opts.AccessToken()
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
 	if err != nil {
 		return err
 	}
-	c := api.NewJobClient(cfg.JobServerUrl, tok)
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
 
 	data, err := ioutil.ReadFile(opts.SpecFile)
 	if err != nil {
@@ -88,7 +121,7 @@ func runRun(opts *RunOptions) error {
 	}
 	// generate a new job name
 	sp.Name = fmt.Sprintf("%s-%s", sp.Name, utils.RandStringRunes(6))
-	err = cmdutil.PrepareJob(sp, c)
+	err = cmdutil.PrepareJob(ctx, sp, c)
 	if err != nil {
 		return err
 	}
@@ -109,7 +142,7 @@ func runRun(opts *RunOptions) error {
 		Cluster: opts.Cluster,
 	}
 
-	job, err := c.Create(obj)
+	job, err := c.Create(ctx, obj)
 	if err != nil {
 		return err
 	}
@@ -119,20 +152,72 @@ func runRun(opts *RunOptions) error {
 		err = utils.OpenInBrowser(jobUrl)
 		return err
 	}
-	outCh, err := c.Logs(job.Name, "", opts.Cluster, true)
+	events, err := c.Logs(ctx, job.Name, api.LogOptions{
+		Cluster: opts.Cluster,
+		Follow:  true,
+	})
+	if err != nil {
+		return err
+	}
+	if opts.Saver != nil {
+		opts.Saver.Job = job.Name
+	}
+	printer := &cmdutil.LogPrinter{IO: opts.IO, Saver: opts.Saver}
+	if err := printer.Print(ctx, events); err != nil {
+		return err
+	}
+
+	// the log ends when the container exits, the job may still retry it
+	status, err := cmdutil.WaitJobPhase(ctx, c, job.Name, opts.Cluster, api.PhaseSucceeded, nil)
+	if err != nil {
+		return err
+	}
+	if status.Phase == api.PhaseSucceeded {
+		return nil
+	}
+	pods, err := c.Pods(ctx, job.Name, opts.Cluster)
 	if err != nil {
 		return err
 	}
-	for {
-		item, ok := <- outCh
-		if !ok {
-			return nil
+	code, reason := jobExitCode(status, pods)
+	return &cmdutil.ExitCodeError{
+		Code: code,
+		Err:  fmt.Errorf("job %s failed: %s", job.Name, reason),
+	}
+}
+
+// jobExitCode returns the exit code of kaectl for a failed job and why the
+// job failed. It relies on the reason of the terminated container being sent
+// as Pod.Reason, see api.Pod.
+func jobExitCode(status *api.JobStatus, pods []*api.Pod) (int, string) {
+	for _, cond := range status.Conditions {
+		if cond.Type == "Failed" && cond.Reason == "DeadlineExceeded" {
+			return cmdutil.ExitJobDeadlineExceeded, "it ran longer than activeDeadlineSeconds"
 		}
-		switch v := item.(type) {
-		case error:
-			return v
-		case string:
-			fmt.Printf("%s", v)
+	}
+
+	// the last failed pod tells why the job failed after retrying
+	var last *api.Pod
+	for _, pod := range pods {
+		if pod.Phase == api.PhaseFailed && (last == nil || !pod.StartTime.Before(last.StartTime)) {
+			last = pod
 		}
 	}
+	switch {
+	case last == nil:
+		return cmdutil.ExitError, "no failed pod was found, run `kaectl job describe` for details"
+	case last.Reason == "OOMKilled" && last.ExitCode != nil:
+		return cmdutil.ExitJobOOMKilled, fmt.Sprintf("the container of pod %s ran out of memory", last.Name)
+	case last.Reason == "Evicted":
+		return cmdutil.ExitJobEvicted, fmt.Sprintf("pod %s was evicted", last.Name)
+	case last.ExitCode != nil && *last.ExitCode == 128+9:
+		// killed by SIGKILL without OOMKilled, e.g. by kill -9
+		return *last.ExitCode, fmt.Sprintf("the container of pod %s was killed with exit code %d", last.Name, *last.ExitCode)
+	case last.ExitCode != nil && *last.ExitCode != 0:
+		return *last.ExitCode, fmt.Sprintf("the container of pod %s exited with code %d", last.Name, *last.ExitCode)
+	case last.Reason != "":
+		return cmdutil.ExitError, fmt.Sprintf("pod %s failed with reason %s", last.Name, last.Reason)
+	default:
+		return cmdutil.ExitError, fmt.Sprintf("pod %s failed", last.Name)
+	}
 }
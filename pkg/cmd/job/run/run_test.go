@@ -0,0 +1,104 @@
+package run
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+)
+
+func Test_jobExitCode(t *testing.T) {
+	code := func(n int) *int { return &n }
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	failedStatus := &api.JobStatus{
+		Phase:      api.PhaseFailed,
+		Conditions: []api.JobCondition{{Type: "Failed", Status: "True", Reason: "BackoffLimitExceeded"}},
+	}
+	tests := []struct {
+		name       string
+		status     *api.JobStatus
+		pods       []*api.Pod
+		wantCode   int
+		wantReason string
+	}{
+		{
+			name: "deadline exceeded",
+			status: &api.JobStatus{
+				Phase:      api.PhaseFailed,
+				Conditions: []api.JobCondition{{Type: "Failed", Status: "True", Reason: "DeadlineExceeded"}},
+			},
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, ExitCode: code(1)}},
+			wantCode:   cmdutil.ExitJobDeadlineExceeded,
+			wantReason: "it ran longer than activeDeadlineSeconds",
+		},
+		{
+			name:       "OOMKilled",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, ExitCode: code(137), Reason: "OOMKilled"}},
+			wantCode:   cmdutil.ExitJobOOMKilled,
+			wantReason: "the container of pod my-job-a ran out of memory",
+		},
+		{
+			name:       "OOMKilled without exit code",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, Reason: "OOMKilled"}},
+			wantCode:   cmdutil.ExitError,
+			wantReason: "pod my-job-a failed with reason OOMKilled",
+		},
+		{
+			name:       "killed",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, ExitCode: code(137), Reason: "Error"}},
+			wantCode:   137,
+			wantReason: "the container of pod my-job-a was killed with exit code 137",
+		},
+		{
+			name:       "evicted",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, Reason: "Evicted"}},
+			wantCode:   cmdutil.ExitJobEvicted,
+			wantReason: "pod my-job-a was evicted",
+		},
+		{
+			name:   "exit code of the last failed pod",
+			status: failedStatus,
+			pods: []*api.Pod{
+				{Name: "my-job-a", Phase: api.PhaseFailed, StartTime: start.Add(time.Minute), ExitCode: code(42), Reason: "Error"},
+				{Name: "my-job-b", Phase: api.PhaseFailed, StartTime: start, ExitCode: code(3), Reason: "Error"},
+				{Name: "my-job-c", Phase: api.PhaseRunning, StartTime: start.Add(time.Hour)},
+			},
+			wantCode:   42,
+			wantReason: "the container of pod my-job-a exited with code 42",
+		},
+		{
+			name:       "reason without exit code",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, Reason: "NodeLost"}},
+			wantCode:   cmdutil.ExitError,
+			wantReason: "pod my-job-a failed with reason NodeLost",
+		},
+		{
+			name:       "no reason",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseFailed, ExitCode: code(0)}},
+			wantCode:   cmdutil.ExitError,
+			wantReason: "pod my-job-a failed",
+		},
+		{
+			name:       "no failed pod",
+			status:     failedStatus,
+			pods:       []*api.Pod{{Name: "my-job-a", Phase: api.PhaseSucceeded, ExitCode: code(0)}},
+			wantCode:   cmdutil.ExitError,
+			wantReason: "no failed pod was found, run `kaectl job describe` for details",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, reason := jobExitCode(tt.status, tt.pods)
+			if code != tt.wantCode || reason != tt.wantReason {
+				t.Errorf("jobExitCode() = %d, %q, want %d, %q", code, reason, tt.wantCode, tt.wantReason)
+			}
+		})
+	}
+}
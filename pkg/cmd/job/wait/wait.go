@@ -0,0 +1,186 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/briandowns/spinner"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+	"github.com/spf13/cobra"
+)
+
+type WaitOptions struct {
+	HttpClient  func() (*http.Client, error)
+	Config      func() (*config.CmdConfig, error)
+	AccessToken func(context.Context) (string, error)
+	IO          *iostreams.IOStreams
+
+	Name    string
+	Cluster string
+	For     string
+	Timeout time.Duration
+}
+
+// waitPhases maps the values of --for to the awaited phase, the values read
+// as "waiting for job to be <value>"
+var waitPhases = map[string]string{
+	"complete": api.PhaseSucceeded,
+	"failed":   api.PhaseFailed,
+	"running":  api.PhaseRunning,
+}
+
+func NewCmdWait(f *cmdutil.Factory, runF func(*WaitOptions) error) *cobra.Command {
+	opts := &WaitOptions{
+		IO:          f.IOStreams,
+		HttpClient:  f.HttpClient,
+		Config:      f.Config,
+		AccessToken: f.GetAccessToken,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "wait <name>",
+		Short: "Wait for a job to complete, fail or run",
+		Long: heredoc.Doc(`
+			Wait until a job is in the given state, forever unless --timeout is given.
+
+			kaectl exits with 0 when the job reaches the state, 9 when it reaches a state
+			from which the awaited one can't be reached, e.g. it failed while waiting
+			for it to be complete, and 124 when --timeout expires. A job which already
+			succeeded satisfies --for=running as its pods must have run, a failed job
+			doesn't as it may have failed before any pod ran, e.g. when the image
+			can't be pulled.
+		`),
+		Example: heredoc.Doc(`
+	 		# wait up to an hour for a job to complete
+	 		$ kaectl job wait my-job --for=complete --timeout 1h
+
+	 		# run the evaluation only when training succeeded
+	 		$ kaectl job wait train --for=complete && kaectl job run "python eval.py"
+	   `),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Name = args[0]
+			if _, ok := waitPhases[opts.For]; !ok {
+				return &cmdutil.FlagError{Err: fmt.Errorf("invalid value for `--for`: %q, must be one of complete, failed or running", opts.For)}
+			}
+			if opts.Timeout < 0 {
+				return &cmdutil.FlagError{Err: errors.New("invalid value for `--timeout`: must not be negative")}
+			}
+
+			if runF != nil {
+				return runF(opts)
+			}
+
+			return waitRun(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Cluster, "cluster", "c", "", "cluster of the job, the default is the cluster the job was created in")
+	cmd.Flags().StringVar(&opts.For, "for", "complete", "the state to wait for: complete, failed or running")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 0, "how long to wait for the job, 0 waits forever")
+
+	return cmd
+}
+
+func waitRun(ctx context.Context, opts *WaitOptions) error {
+	cfg, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	tok, err := opts.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	httpClient, err := opts.HttpClient()
+	if err != nil {
+		return err
+	}
+	c := api.NewJobClient(httpClient, cfg.JobServerUrl, tok)
+
+	job, err := c.Get(ctx, opts.Name)
+	if err != nil {
+		return err
+	}
+	cluster := opts.Cluster
+	if cluster == "" {
+		cluster = job.Cluster
+	}
+
+	waitCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var s *spinner.Spinner
+	if opts.IO.IsStderrTTY() {
+		s = utils.Spinner(opts.IO.ErrOut)
+		s.Suffix = fmt.Sprintf(" waiting for job %s to be %s", opts.Name, opts.For)
+		utils.StartSpinner(s)
+	}
+	onPhase := func(phase string) {
+		if s == nil {
+			return
+		}
+		s.Lock()
+		s.Suffix = fmt.Sprintf(" waiting for job %s to be %s, it is %s", opts.Name, opts.For, strings.ToLower(phase))
+		s.Unlock()
+	}
+	status, err := cmdutil.WaitJobPhase(waitCtx, c, opts.Name, cluster, waitPhases[opts.For], onPhase)
+	if s != nil {
+		utils.StopSpinner(s)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		return &cmdutil.ExitCodeError{
+			Code: cmdutil.ExitTimeout,
+			Err:  fmt.Errorf("timed out after %s waiting for job %s to be %s", opts.Timeout, opts.Name, opts.For),
+		}
+	case err != nil:
+		return err
+	case !reached(opts.For, status.Phase):
+		return &cmdutil.ExitCodeError{
+			Code: cmdutil.ExitWaitFailed,
+			Err:  fmt.Errorf("job %s %s while waiting for it to be %s", opts.Name, describePhase(status), opts.For),
+		}
+	}
+	if opts.IO.IsStderrTTY() {
+		fmt.Fprintf(opts.IO.ErrOut, "%s job %s %s\n", utils.GreenCheck(), opts.Name, describePhase(status))
+	}
+	return nil
+}
+
+// reached reports whether a job in phase satisfies --for. A succeeded job
+// satisfies running as its pods must have run, it may finish between two
+// polls. A failed job may have failed before any pod ran, so it doesn't.
+func reached(waitFor, phase string) bool {
+	return phase == waitPhases[waitFor] || (waitFor == "running" && phase == api.PhaseSucceeded)
+}
+
+// describePhase explains the phase of a job, e.g. "failed (BackoffLimitExceeded)"
+func describePhase(status *api.JobStatus) string {
+	if status.Phase == api.PhaseRunning {
+		return "is running"
+	}
+	desc := strings.ToLower(status.Phase)
+	for _, cond := range status.Conditions {
+		if cond.Type == status.Phase || (cond.Type == "Complete" && status.Phase == api.PhaseSucceeded) {
+			if cond.Reason != "" {
+				desc += " (" + cond.Reason + ")"
+			}
+			break
+		}
+	}
+	return desc
+}
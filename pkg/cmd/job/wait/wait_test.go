@@ -0,0 +1,212 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
+	"github.com/kaecloud/kaectl/pkg/cmdutil"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/spf13/cobra"
+)
+
+// newJobServer returns a server of the job my-job in cluster mycluster
+// replying statuses in order to the status requests, the last one is
+// repeated
+func newJobServer(t *testing.T, statuses []string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/jobs/my-job":
+			w.Write([]byte(`{"name": "my-job", "cluster": "mycluster"}`))
+		case "/api/v1/jobs/my-job/status":
+			if got := r.URL.Query().Get("cluster"); got != "mycluster" {
+				t.Errorf("cluster = %q, want mycluster", got)
+			}
+			mu.Lock()
+			status := statuses[0]
+			if len(statuses) > 1 {
+				statuses = statuses[1:]
+			}
+			mu.Unlock()
+			w.Write([]byte(status))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_waitRun(t *testing.T) {
+	interval := cmdutil.JobPollInterval
+	cmdutil.JobPollInterval = time.Millisecond
+	defer func() { cmdutil.JobPollInterval = interval }()
+
+	const (
+		pending   = `{"phase": "Pending"}`
+		running   = `{"phase": "Running"}`
+		succeeded = `{"phase": "Succeeded", "conditions": [{"type": "Complete", "status": "True"}]}`
+		failed    = `{"phase": "Failed", "conditions": [{"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"}]}`
+	)
+	tests := []struct {
+		name     string
+		waitFor  string
+		timeout  time.Duration
+		statuses []string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "complete", waitFor: "complete", statuses: []string{pending, running, succeeded}},
+		{name: "running", waitFor: "running", statuses: []string{pending, running}},
+		{name: "running after success", waitFor: "running", statuses: []string{succeeded}},
+		{name: "failed", waitFor: "failed", statuses: []string{running, failed}},
+		{
+			name:     "failed while waiting for complete",
+			waitFor:  "complete",
+			statuses: []string{running, failed},
+			wantCode: cmdutil.ExitWaitFailed,
+			wantErr:  "job my-job failed (BackoffLimitExceeded) while waiting for it to be complete",
+		},
+		{
+			name:     "succeeded while waiting for failed",
+			waitFor:  "failed",
+			statuses: []string{succeeded},
+			wantCode: cmdutil.ExitWaitFailed,
+			wantErr:  "job my-job succeeded while waiting for it to be failed",
+		},
+		{
+			name:     "timeout",
+			waitFor:  "complete",
+			timeout:  20 * time.Millisecond,
+			statuses: []string{running},
+			wantCode: cmdutil.ExitTimeout,
+			wantErr:  "timed out after 20ms waiting for job my-job to be complete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newJobServer(t, tt.statuses)
+			defer srv.Close()
+
+			io, _, _, _ := iostreams.Test()
+			opts := &WaitOptions{
+				HttpClient: func() (*http.Client, error) {
+					return api.NewHTTPClient(), nil
+				},
+				Config: func() (*config.CmdConfig, error) {
+					return &config.CmdConfig{JobServerUrl: srv.URL}, nil
+				},
+				AccessToken: func(context.Context) (string, error) {
+					return "TOKEN", nil
+				},
+				IO:      io,
+				Name:    "my-job",
+				For:     tt.waitFor,
+				Timeout: tt.timeout,
+			}
+			err := waitRun(context.Background(), opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var codeErr *cmdutil.ExitCodeError
+			if !errors.As(err, &codeErr) || codeErr.Code != tt.wantCode {
+				t.Fatalf("error = %v, want exit code %d", err, tt.wantCode)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_waitRun_canceled(t *testing.T) {
+	srv := newJobServer(t, []string{`{"phase": "Running"}`})
+	defer srv.Close()
+
+	io, _, _, _ := iostreams.Test()
+	opts := &WaitOptions{
+		HttpClient:  func() (*http.Client, error) { return api.NewHTTPClient(), nil },
+		Config:      func() (*config.CmdConfig, error) { return &config.CmdConfig{JobServerUrl: srv.URL}, nil },
+		AccessToken: func(context.Context) (string, error) { return "TOKEN", nil },
+		IO:          io,
+		Name:        "my-job",
+		For:         "complete",
+		Timeout:     time.Hour,
+	}
+	// a deadline of the parent context is not --timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := waitRun(ctx, opts)
+	if !errors.Is(err, context.DeadlineExceeded) || cmdutil.ExitCode(err) == cmdutil.ExitTimeout {
+		t.Errorf("error = %v, want the deadline of the parent context", err)
+	}
+}
+
+func Test_describePhase(t *testing.T) {
+	tests := []struct {
+		status *api.JobStatus
+		want   string
+	}{
+		{status: &api.JobStatus{Phase: api.PhaseRunning}, want: "is running"},
+		{status: &api.JobStatus{Phase: api.PhasePending}, want: "pending"},
+		{status: &api.JobStatus{Phase: api.PhaseSucceeded}, want: "succeeded"},
+		{
+			status: &api.JobStatus{Phase: api.PhaseSucceeded, Conditions: []api.JobCondition{{Type: "Complete", Reason: "Done"}}},
+			want:   "succeeded (Done)",
+		},
+		{
+			status: &api.JobStatus{Phase: api.PhaseFailed, Conditions: []api.JobCondition{
+				{Type: "Suspended", Reason: "Paused"},
+				{Type: "Failed", Reason: "DeadlineExceeded"},
+			}},
+			want: "failed (DeadlineExceeded)",
+		},
+		{
+			status: &api.JobStatus{Phase: api.PhaseFailed, Conditions: []api.JobCondition{{Type: "Failed"}}},
+			want:   "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := describePhase(tt.status); got != tt.want {
+				t.Errorf("describePhase() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCmdWait_timeout(t *testing.T) {
+	io, _, _, _ := iostreams.Test()
+	f := &cmdutil.Factory{IOStreams: io}
+	// the root command has a --timeout for every request
+	root := &cobra.Command{Use: "kaectl"}
+	root.PersistentFlags().DurationVar(&f.Timeout, "timeout", 30*time.Second, "")
+
+	var got *WaitOptions
+	cmd := NewCmdWait(f, func(opts *WaitOptions) error {
+		got = opts
+		return nil
+	})
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"wait", "my-job", "--timeout", "1h"})
+	root.SetOut(ioutil.Discard)
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Timeout != time.Hour {
+		t.Errorf("Timeout = %s, want 1h", got.Timeout)
+	}
+	if f.Timeout != 30*time.Second {
+		t.Errorf("request timeout = %s, want it unchanged", f.Timeout)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/kaecloud/kaectl/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"regexp"
 	"strings"
 )
@@ -24,7 +25,8 @@ func rootUsageFunc(command *cobra.Command) error {
 		return nil
 	}
 
-	flagUsages := command.LocalFlags().FlagUsages()
+	localFlags, _ := splitFlags(command)
+	flagUsages := localFlags.FlagUsages()
 	if flagUsages != "" {
 		command.Println("\n\nFlags:")
 		command.Print(indent(dedent(flagUsages), "  "))
@@ -32,6 +34,23 @@ func rootUsageFunc(command *cobra.Command) error {
 	return nil
 }
 
+// splitFlags returns the local and the inherited flags of command. Unlike
+// LocalFlags, a flag of command named like a persistent flag of a parent is
+// local, it replaces the parent's one as in `job wait --timeout`.
+func splitFlags(command *cobra.Command) (*pflag.FlagSet, *pflag.FlagSet) {
+	local := pflag.NewFlagSet(command.Name(), pflag.ContinueOnError)
+	local.AddFlagSet(command.LocalFlags())
+	inherited := pflag.NewFlagSet(command.Name(), pflag.ContinueOnError)
+	command.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if own := command.Flags().Lookup(f.Name); own != nil && own != f {
+			local.AddFlag(own)
+		} else {
+			inherited.AddFlag(f)
+		}
+	})
+	return local, inherited
+}
+
 var hasFailed bool
 
 // HasFailed signals that the main process should exit with non-zero status
@@ -120,11 +139,12 @@ func rootHelpFunc(command *cobra.Command, args []string) {
 		helpEntries = append(helpEntries, helpEntry{"ADDITIONAL COMMANDS", strings.Join(additionalCommands, "\n")})
 	}
 
-	flagUsages := command.LocalFlags().FlagUsages()
+	localFlags, inheritedFlags := splitFlags(command)
+	flagUsages := localFlags.FlagUsages()
 	if flagUsages != "" {
 		helpEntries = append(helpEntries, helpEntry{"FLAGS", dedent(flagUsages)})
 	}
-	inheritedFlagUsages := command.InheritedFlags().FlagUsages()
+	inheritedFlagUsages := inheritedFlags.FlagUsages()
 	if inheritedFlagUsages != "" {
 		helpEntries = append(helpEntries, helpEntry{"INHERITED FLAGS", dedent(inheritedFlagUsages)})
 	}
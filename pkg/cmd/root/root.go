@@ -3,10 +3,16 @@ package root
 import (
 	"fmt"
 	"github.com/MakeNowJust/heredoc"
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/internal/config"
 	"github.com/kaecloud/kaectl/pkg/cmdutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"strings"
+	"time"
+	appCmd "github.com/kaecloud/kaectl/pkg/cmd/app"
+	authCmd "github.com/kaecloud/kaectl/pkg/cmd/auth"
+	configCmd "github.com/kaecloud/kaectl/pkg/cmd/config"
 	jobCmd "github.com/kaecloud/kaectl/pkg/cmd/job"
 )
 
@@ -21,24 +27,30 @@ func NewCmdRoot(f *cmdutil.Factory, version, buildDate string) *cobra.Command {
 		Example: heredoc.Doc(`
 			$ kaectl job create
 			$ kaectl job get
+			$ kaectl app deploy
 		`),
 		Annotations: map[string]string{
 			"help:feedback": heredoc.Doc(`
-				Open an issue using “gh issue create -R cli/cli”
+				Open an issue at https://github.com/kaecloud/kaectl/issues
 			`),
 			"help:environment": heredoc.Doc(`
-				GITHUB_TOKEN: an authentication token for API requests. Setting this avoids being
-				prompted to authenticate and overrides any previously stored credentials.
-	
-				GH_REPO: specify the GitHub repository in the "[HOST/]OWNER/REPO" format for commands
-				that otherwise operate on a local repository.
-				GH_HOST: specify the GitHub hostname for commands that would otherwise assume
-				the "github.com" host when not in a context of an existing repository.
-	
+				KAE_TOKEN: an access token for API requests. Setting this avoids logging in and
+				overrides any previously stored credentials.
+
+				KAE_CLIENT_ID, KAE_CLIENT_SECRET: authenticate as the service account of an SSO
+				client with the client credentials grant, overriding sso_client_id and
+				sso_client_secret in config file.
+
+				KAE_CONFIG: path of the config file, the default is ~/.kae/config.yaml.
+
+				KAE_<KEY>: override a key of config file, e.g. KAE_JOB_SERVER_URL overrides
+				job_server_url. Global flags such as --job-server-url take precedence.
+
 				BROWSER: the web browser to use for opening links.
 	
-				DEBUG: set to any value to enable verbose output to standard error. Include values "api"
-				or "oauth" to print detailed information about HTTP requests or authentication flow.
+				DEBUG: set to any value to log the HTTP requests to the KAE servers and SSO to standard
+				error. Include value "api" to log their headers and bodies as well, credentials are
+				redacted.
 	
 				GLAMOUR_STYLE: the style to use for rendering Markdown. See
 				https://github.com/charmbracelet/glamour#styles
@@ -69,6 +81,13 @@ func NewCmdRoot(f *cmdutil.Factory, version, buildDate string) *cobra.Command {
 	cmd.SetErr(f.IOStreams.ErrOut)
 
 	cmd.PersistentFlags().Bool("help", false, "Show help for command")
+	cmd.PersistentFlags().StringVar(&f.ConfigFilename, "config", "", "Path of the config file (default \"~/.kae/config.yaml\")")
+	cmd.PersistentFlags().StringVar(&f.Context, "context", "", "The context in config file to use")
+	cmd.PersistentFlags().DurationVar(&f.Timeout, "timeout", 30*time.Second, "Time to wait for the server to respond, 0 means waiting forever")
+	cmd.PersistentFlags().IntVar(&f.Retries, "retries", api.DefaultRetryPolicy.MaxRetries, "Number of times to retry a request failed with a network error or an unavailable server")
+	for _, key := range config.Keys() {
+		cmd.PersistentFlags().StringVar(f.ConfigOverrides.Ptr(key), config.FlagName(key), "", config.Description(key))
+	}
 	cmd.SetHelpFunc(rootHelpFunc)
 	cmd.SetUsageFunc(rootUsageFunc)
 
@@ -84,6 +103,9 @@ func NewCmdRoot(f *cmdutil.Factory, version, buildDate string) *cobra.Command {
 	// CHILD COMMANDS
 
 	cmd.AddCommand(jobCmd.NewCmdJob(f))
+	cmd.AddCommand(appCmd.NewCmdApp(f))
+	cmd.AddCommand(authCmd.NewCmdAuth(f))
+	cmd.AddCommand(configCmd.NewCmdConfig(f))
 	cmd.AddCommand(NewCmdCompletion(f.IOStreams))
 
 	return cmd
@@ -1,6 +1,11 @@
 package cmdutil
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	"github.com/kaecloud/kaectl/api"
+)
 
 // FlagError is the kind of error raised in flag processing
 type FlagError struct {
@@ -16,4 +21,73 @@ func (fe FlagError) Unwrap() error {
 }
 
 // SilentError is an error that triggers exit code 1 without any error messaging
-var SilentError = errors.New("SilentError")
\ No newline at end of file
+var SilentError = errors.New("SilentError")
+
+// ExitCodeError makes kaectl exit with Code after printing Err, Err may be
+// SilentError to print nothing
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// Exit codes of kaectl, scripts can tell the errors of the KAE servers apart
+// with them
+const (
+	ExitOK           = 0
+	ExitError        = 1
+	ExitUnauthorized = 3
+	ExitForbidden    = 4
+	ExitNotFound     = 5
+	ExitConflict     = 6
+	ExitValidation   = 7
+	ExitServerError  = 8
+	// ExitWaitFailed is returned when the job reached a state from which the
+	// awaited one can't be reached, e.g. it failed while waiting to complete
+	ExitWaitFailed = 9
+	// ExitTimeout is returned when waiting timed out, like timeout(1)
+	ExitTimeout = 124
+	ExitCancel  = 130
+
+	// ExitJobDeadlineExceeded, ExitJobOOMKilled and ExitJobEvicted are
+	// returned by job run when the job failed for these reasons, they are
+	// the codes of a local command stopped by timeout(1), SIGKILL and SIGTERM
+	ExitJobDeadlineExceeded = 124
+	ExitJobOOMKilled        = 137
+	ExitJobEvicted          = 143
+)
+
+// ExitCode returns the exit code of kaectl when a command failed with err
+func ExitCode(err error) int {
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitCancel
+	case errors.As(err, &api.UnauthorizedError{}):
+		return ExitUnauthorized
+	case errors.As(err, &api.ForbiddenError{}):
+		return ExitForbidden
+	case errors.As(err, &api.NotFoundError{}):
+		return ExitNotFound
+	case errors.As(err, &api.ConflictError{}):
+		return ExitConflict
+	case errors.As(err, &api.ValidationError{}):
+		return ExitValidation
+	case errors.As(err, &api.ServerError{}):
+		return ExitServerError
+	default:
+		return ExitError
+	}
+}
@@ -1,54 +1,150 @@
 package cmdutil
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kaecloud/kaectl/api"
 	"github.com/kaecloud/kaectl/auth"
 	"github.com/kaecloud/kaectl/internal/config"
 	"github.com/kaecloud/kaectl/pkg/iostreams"
 	"net/http"
+	"net/url"
+	"os"
+	"time"
 )
 
 type Factory struct {
 	IOStreams  *iostreams.IOStreams
 	HttpClient func() (*http.Client, error)
 	Config     func() (*config.CmdConfig, error)
-	GetAccessToken   func() (string, error)
+	ConfigFile func() (*config.File, error)
+	GetAccessToken   func(context.Context) (string, error)
+	TokenCache func() (*auth.TokenCache, error)
+
+	// Context is the name of the context selected by the --context flag
+	Context string
+	// ConfigFilename is the config file given by the --config flag
+	ConfigFilename string
+	// ConfigOverrides holds the config values given by global flags
+	ConfigOverrides config.CmdConfig
+	// Timeout is the time to wait for the server given by the --timeout
+	// flag, 0 means waiting forever
+	Timeout time.Duration
+	// Retries is the number of times a failed idempotent request is retried
+	// given by the --retries flag
+	Retries int
 }
 
 func NewFactory(appVersion string) *Factory {
 	io := iostreams.System()
+	f := &Factory{
+		IOStreams: io,
+	}
 
+	var cachedConfigFile *config.File
+	var configFileError error
 	var cachedConfig *config.CmdConfig
 	var configError error
 	var tok *auth.Token
+	configFileFunc := func() (*config.File, error) {
+		if cachedConfigFile != nil || configFileError != nil {
+			return cachedConfigFile, configFileError
+		}
+		cachedConfigFile, configFileError = config.LoadFile(config.Filename(f.ConfigFilename))
+		return cachedConfigFile, configFileError
+	}
+
 	configFunc := func() (*config.CmdConfig, error) {
 		if cachedConfig != nil || configError != nil {
 			return cachedConfig, configError
 		}
-		cachedConfig, configError = config.LoadCmdConfig()
-		// if errors.Is(configError, os.ErrNotExist) {
-		// 	cachedConfig = config.NewBlankConfig()
-		// 	configError = nil
-		// }
+		// environment variables and flags may provide the whole config,
+		// so a missing config file is only an error when they don't
+		file, err := configFileFunc()
+		var notExistErr *config.NotExistError
+		if errors.As(err, &notExistErr) {
+			file = &config.File{Filename: notExistErr.Filename}
+		} else if err != nil {
+			return nil, err
+		}
+		var origins config.Origins
+		cachedConfig, origins, configError = file.Resolve(f.Context, &f.ConfigOverrides)
+		if configError == nil && notExistErr != nil && len(origins) == 0 {
+			cachedConfig, configError = nil, notExistErr
+		}
 		return cachedConfig, configError
 	}
 
-	accessTokenFunc := func() (string, error) {
+	// every context has its own token cache, so switching contexts doesn't
+	// require logging in again
+	tokenCacheFunc := func() (*auth.TokenCache, error) {
+		name := f.Context
+		if file, err := configFileFunc(); err == nil {
+			name = file.ContextName(f.Context)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if name == "" {
+			return auth.NewTokenCache(auth.DefaultTokenCacheFile), nil
+		}
+		return auth.NewTokenCache(fmt.Sprintf(auth.ContextTokenCacheFile, url.PathEscape(name))), nil
+	}
+
+	// The access token is chosen in the following order:
+	// 1. KAE_TOKEN environment variable, used as is;
+	// 2. the token cache, refreshed when the access token has expired;
+	// 3. a client credentials login, when sso_client_secret or
+	//    KAE_CLIENT_SECRET is set;
+	// 4. a password login, when sso_password is set.
+	accessTokenFunc := func(ctx context.Context) (string, error) {
+		if envToken := os.Getenv(auth.EnvToken); envToken != "" {
+			return envToken, nil
+		}
 		if tok == nil {
 			cfg, err := configFunc()
 			if err != nil {
 				return "", err
 			}
-			tok, err = auth.GetAccessToken(cfg)
+			cache, err := tokenCacheFunc()
+			if err != nil {
+				return "", err
+			}
+			tok, err = auth.GetCachedAccessToken(ctx, cfg, cache)
 			if err != nil {
 				return "", err
 			}
 		}
 		return tok.AccessToken, nil
 	}
-	return &Factory{
-		IOStreams:      io,
-		Config:         configFunc,
-		GetAccessToken: accessTokenFunc,
+
+	httpClientFunc := func() (*http.Client, error) {
+		cfg, err := configFunc()
+		if err != nil {
+			return nil, err
+		}
+		transportOpts, err := api.TransportOptionsFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transportOpts.Timeout = f.Timeout
+		tr, err := api.NewTransport(transportOpts)
+		if err != nil {
+			return nil, err
+		}
+		retryPolicy := api.DefaultRetryPolicy
+		retryPolicy.MaxRetries = f.Retries
+		return api.NewHTTPClient(
+			api.ReplaceTripper(tr),
+			api.VerboseLogFromEnv(io.ErrOut),
+			api.WithRetry(retryPolicy),
+		), nil
 	}
-}
 
+	f.HttpClient = httpClientFunc
+	f.Config = configFunc
+	f.ConfigFile = configFileFunc
+	f.GetAccessToken = accessTokenFunc
+	f.TokenCache = tokenCacheFunc
+	return f
+}
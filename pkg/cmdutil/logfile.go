@@ -0,0 +1,277 @@
+package cmdutil
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogFlushInterval is how often LogPrinter flushes the lines compressed by a
+// LogSaver, so a killed kaectl loses at most the lines of the last interval
+var LogFlushInterval = 5 * time.Second
+
+// openSavers are the savers with open files, see CloseLogSavers
+var openSavers = struct {
+	sync.Mutex
+	m map[*LogSaver]bool
+}{m: map[*LogSaver]bool{}}
+
+// CloseLogSavers closes the open files of all savers, it is called before
+// exiting without returning from the command, e.g. on the second interrupt
+func CloseLogSavers() {
+	openSavers.Lock()
+	savers := make([]*LogSaver, 0, len(openSavers.m))
+	for s := range openSavers.m {
+		savers = append(savers, s)
+	}
+	openSavers.Unlock()
+	for _, s := range savers {
+		_ = s.Close()
+	}
+}
+
+// LogSaver saves the lines of log streams to files, either all lines to
+// File or the lines of every pod to its own file in Dir
+type LogSaver struct {
+	// File is the file of all lines, it is used when Dir is empty
+	File string
+	// Dir is the directory of the files named <pod>.log
+	Dir string
+	// Job names the file in Dir of the lines without pod
+	Job string
+	// Gzip compresses the files, the files in Dir get the .gz suffix
+	Gzip bool
+	// MaxSize rotates a file when its size reaches MaxSize bytes, 0 disables
+	// rotation
+	MaxSize int64
+
+	// mu guards files as CloseLogSavers may close them while writing
+	mu     sync.Mutex
+	files  map[string]*logFile
+	closed bool
+}
+
+// NewLogSaver converts the values of --save, --output-dir, --gzip and
+// --max-size flags to a LogSaver, it returns nil when logs are not saved.
+// maxSizeMB is in megabytes.
+func NewLogSaver(file, dir string, gzip bool, maxSizeMB int) (*LogSaver, error) {
+	switch {
+	case file != "" && dir != "":
+		return nil, &FlagError{Err: errors.New("specify only one of `--save` or `--output-dir`")}
+	case maxSizeMB < 0:
+		return nil, &FlagError{Err: errors.New("invalid value for `--max-size`: must not be negative")}
+	case file == "" && dir == "":
+		if gzip || maxSizeMB > 0 {
+			return nil, &FlagError{Err: errors.New("`--gzip` and `--max-size` require `--save` or `--output-dir`")}
+		}
+		return nil, nil
+	}
+	return &LogSaver{
+		File:    file,
+		Dir:     dir,
+		Gzip:    gzip,
+		MaxSize: int64(maxSizeMB) << 20,
+	}, nil
+}
+
+// Write appends a line of pod to its file, text is the line without newline
+func (s *LogSaver) Write(pod, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("failed to save log: the files are closed")
+	}
+	path := s.path(pod)
+	f, ok := s.files[path]
+	if !ok {
+		f = &logFile{path: path, gzip: s.Gzip, maxSize: s.MaxSize}
+		if err := f.open(); err != nil {
+			return err
+		}
+		if s.files == nil {
+			s.files = map[string]*logFile{}
+			openSavers.Lock()
+			openSavers.m[s] = true
+			openSavers.Unlock()
+		}
+		s.files[path] = f
+	}
+	return f.writeLine(text)
+}
+
+// Flush writes the lines buffered by gzip to the files, a file can then be
+// decompressed up to the last line although it is not closed yet
+func (s *LogSaver) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.files {
+		if err := f.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes all files, it returns the first error. Lines
+// written after Close are not saved.
+func (s *LogSaver) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for _, f := range s.files {
+		if err := f.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	s.files = nil
+	s.closed = true
+	openSavers.Lock()
+	delete(openSavers.m, s)
+	openSavers.Unlock()
+	return firstErr
+}
+
+func (s *LogSaver) path(pod string) string {
+	if s.Dir == "" {
+		return s.File
+	}
+	name := pod
+	if name == "" {
+		name = s.Job
+	}
+	name += ".log"
+	if s.Gzip {
+		name += ".gz"
+	}
+	return filepath.Join(s.Dir, name)
+}
+
+// logFile is a log file rotated by size, the rotated files are numbered from
+// the oldest, e.g. my-pod.1.log, my-pod.2.log. Lines are appended to an
+// existing file, a gzip file then gets another gzip member which gunzip reads
+// as usual.
+type logFile struct {
+	path    string
+	gzip    bool
+	maxSize int64
+
+	f  *os.File
+	gz *gzip.Writer
+	w  io.Writer
+	// size is the size of the file on disk, it lags behind the lines
+	// buffered by gzip
+	size int64
+	// dirty is set when lines were written since the last flush
+	dirty bool
+}
+
+func (f *logFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.f = file
+	f.size = info.Size()
+	f.w = &countingWriter{w: file, n: &f.size}
+	if f.gzip {
+		f.gz = gzip.NewWriter(f.w)
+		f.w = f.gz
+	}
+	return nil
+}
+
+func (f *logFile) writeLine(text string) error {
+	if f.maxSize > 0 && f.size >= f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	f.dirty = true
+	_, err := io.WriteString(f.w, text+"\n")
+	return err
+}
+
+// flush writes the lines buffered by gzip, the other files are not buffered
+func (f *logFile) flush() error {
+	if f.gz == nil || !f.dirty {
+		return nil
+	}
+	f.dirty = false
+	if err := f.gz.Flush(); err != nil {
+		return fmt.Errorf("failed to save log to %s: %w", f.path, err)
+	}
+	return nil
+}
+
+func (f *logFile) rotate() error {
+	if err := f.close(); err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		name := rotatedName(f.path, n)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			if err := os.Rename(f.path, name); err != nil {
+				return err
+			}
+			return f.open()
+		}
+	}
+}
+
+func (f *logFile) close() error {
+	if f.f == nil {
+		return nil
+	}
+	var err error
+	if f.gz != nil {
+		err = f.gz.Close()
+	}
+	if closeErr := f.f.Close(); err == nil {
+		err = closeErr
+	}
+	f.f, f.gz, f.w, f.dirty = nil, nil, nil, false
+	if err != nil {
+		return fmt.Errorf("failed to save log to %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// rotatedName inserts n before the extension of path and its .gz suffix,
+// e.g. my-pod.log.gz becomes my-pod.1.log.gz. The dots of pod names are kept
+// in the name, e.g. train.v2.log becomes train.v2.1.log.
+func rotatedName(path string, n int) string {
+	dir, base := filepath.Split(path)
+	var ext string
+	if strings.HasSuffix(base, ".gz") {
+		base, ext = strings.TrimSuffix(base, ".gz"), ".gz"
+	}
+	if i := strings.LastIndexByte(base, '.'); i > 0 {
+		base, ext = base[:i], base[i:]+ext
+	}
+	return fmt.Sprintf("%s%s.%d%s", dir, base, n, ext)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	*cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,235 @@
+package cmdutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// readLogFile returns the content of a saved log, decompressing every gzip
+// member of it
+func readLogFile(t *testing.T, path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return string(data)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", path, err)
+	}
+	content, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", path, err)
+	}
+	return string(content)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestLogSaver_rotate(t *testing.T) {
+	tests := []struct {
+		name      string
+		gzip      bool
+		wantFiles []string
+	}{
+		{
+			name:      "plain",
+			wantFiles: []string{"my.pod-0.1.log", "my.pod-0.2.log", "my.pod-0.log"},
+		},
+		{
+			name:      "gzip",
+			gzip:      true,
+			wantFiles: []string{"my.pod-0.1.log.gz", "my.pod-0.2.log.gz", "my.pod-0.log.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := &LogSaver{Dir: dir, Gzip: tt.gzip, MaxSize: 8}
+			// a line is 5 bytes, so a plain file rotates after 2 lines; gzip
+			// writes only its header until the file is closed, so the size
+			// of a gzip file reaches MaxSize with the header and the file
+			// rotates after every line
+			lines := []string{"line1", "line2", "line3", "line4", "line5"}
+			if tt.gzip {
+				lines = lines[:3]
+			}
+			for _, line := range lines {
+				if err := s.Write("my.pod-0", line); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := s.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := listDir(t, dir); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Fatalf("files = %q, want %q", got, tt.wantFiles)
+			}
+			// the rotated files are numbered from the oldest
+			var got []string
+			for _, name := range tt.wantFiles {
+				got = append(got, readLogFile(t, filepath.Join(dir, name)))
+			}
+			want := []string{"line1\nline2\n", "line3\nline4\n", "line5\n"}
+			if tt.gzip {
+				want = []string{"line1\n", "line2\n", "line3\n"}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("contents = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLogSaver_gzipAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.log.gz")
+	for _, line := range []string{"first run", "second run"} {
+		s := &LogSaver{File: path, Gzip: true}
+		if err := s.Write("", line); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := bytes.NewReader(data)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var members []string
+	for {
+		zr.Multistream(false)
+		content, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		members = append(members, string(content))
+		if err := zr.Reset(r); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if want := []string{"first run\n", "second run\n"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("gzip members = %q, want %q", members, want)
+	}
+	// gunzip reads the members as one stream
+	if got, want := readLogFile(t, path), "first run\nsecond run\n"; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+}
+
+func Test_rotatedName(t *testing.T) {
+	tests := []struct {
+		path string
+		n    int
+		want string
+	}{
+		{path: "logs/my-pod.log", n: 1, want: "logs/my-pod.1.log"},
+		{path: "logs/my-pod.log.gz", n: 2, want: "logs/my-pod.2.log.gz"},
+		{path: "logs/train.v2-abcde.log", n: 1, want: "logs/train.v2-abcde.1.log"},
+		{path: "logs/train.v2-abcde.log.gz", n: 3, want: "logs/train.v2-abcde.3.log.gz"},
+		{path: "run.d/train", n: 1, want: "run.d/train.1"},
+		{path: "train.gz", n: 1, want: "train.1.gz"},
+		{path: ".log", n: 1, want: ".log.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rotatedName(filepath.FromSlash(tt.path), tt.n); got != filepath.FromSlash(tt.want) {
+				t.Errorf("rotatedName(%q, %d) = %q, want %q", tt.path, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_countingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	n := int64(3)
+	cw := &countingWriter{w: &buf, n: &n}
+	for _, s := range []string{"hello", "", " world"} {
+		if _, err := io.WriteString(cw, s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n != 14 {
+		t.Errorf("n = %d, want 14", n)
+	}
+	if buf.String() != "hello world" {
+		t.Errorf("written %q, want %q", buf.String(), "hello world")
+	}
+}
+
+func TestLogSaver_flush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.log.gz")
+	s := &LogSaver{File: path, Gzip: true}
+	defer s.Close()
+	if err := s.Write("", "line1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the file has no gzip trailer yet, but the flushed lines can be read
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := ioutil.ReadAll(zr)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if string(content) != "line1\n" {
+		t.Errorf("flushed content = %q, want %q", content, "line1\n")
+	}
+}
+
+func TestCloseLogSavers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job.log.gz")
+	s := &LogSaver{File: path, Gzip: true}
+	if err := s.Write("", "line1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	CloseLogSavers()
+	if got := readLogFile(t, path); got != "line1\n" {
+		t.Errorf("content = %q, want %q", got, "line1\n")
+	}
+	if err := s.Write("", "line2"); err == nil {
+		t.Errorf("expected an error writing to a closed saver")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("unexpected error closing again: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package cmdutil
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaecloud/kaectl/utils"
+)
+
+// LogFilter selects the log lines to show by regular expressions
+type LogFilter struct {
+	// Include keeps the lines matching any of them, all lines are kept when
+	// it is empty
+	Include []*regexp.Regexp
+	// Exclude drops the lines matching any of them
+	Exclude []*regexp.Regexp
+}
+
+// NewLogFilter converts the values of --grep and --exclude flags to a
+// LogFilter, it returns nil when no line is filtered
+func NewLogFilter(grep, exclude []string) (*LogFilter, error) {
+	if len(grep) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	f := &LogFilter{}
+	var err error
+	if f.Include, err = compilePatterns("--grep", grep); err != nil {
+		return nil, err
+	}
+	if f.Exclude, err = compilePatterns("--exclude", exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compilePatterns(flag string, patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, &FlagError{Err: fmt.Errorf("invalid value for `%s`: %w", flag, err)}
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Match reports whether the line text is kept
+func (f *LogFilter) Match(text string) bool {
+	for _, re := range f.Exclude {
+		if re.MatchString(text) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, re := range f.Include {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns the sorted and non-overlapping ranges of text matched by
+// Include
+func (f *LogFilter) matches(text string) [][]int {
+	var spans [][]int
+	for _, re := range f.Include {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			if m[0] < m[1] {
+				spans = append(spans, m)
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+	merged := spans[:0]
+	for _, m := range spans {
+		if n := len(merged); n > 0 && m[0] <= merged[n-1][1] {
+			if m[1] > merged[n-1][1] {
+				merged[n-1][1] = m[1]
+			}
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+var (
+	errorLevelRE = regexp.MustCompile(`\b(ERROR|FATAL|CRITICAL|PANIC)\b|\blevel=(error|fatal|panic)\b`)
+	warnLevelRE  = regexp.MustCompile(`\b(WARN|WARNING)\b|\blevel=warn(ing)?\b`)
+)
+
+// logLevel returns "error" or "warn" for a line which looks like an error or
+// a warning, it returns "" for the other lines
+func logLevel(text string) string {
+	switch {
+	case errorLevelRE.MatchString(text):
+		return "error"
+	case warnLevelRE.MatchString(text):
+		return "warn"
+	default:
+		return ""
+	}
+}
+
+// levelColorFunc returns the color of a line by its level, it returns nil
+// for the lines which are neither errors nor warnings
+func levelColorFunc(text string) func(string) string {
+	switch logLevel(text) {
+	case "error":
+		return utils.Red
+	case "warn":
+		return utils.Yellow
+	default:
+		return nil
+	}
+}
+
+// highlight colors the line text by its level and marks the matches of
+// filter, filter may be nil
+func highlight(text string, filter *LogFilter) string {
+	lineColor := levelColorFunc(text)
+	var spans [][]int
+	if filter != nil {
+		spans = filter.matches(text)
+	}
+	if lineColor == nil && len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	// the matches are colored apart as a color resets the enclosing one
+	plain := func(s string) {
+		if s != "" && lineColor != nil {
+			s = lineColor(s)
+		}
+		b.WriteString(s)
+	}
+	last := 0
+	for _, m := range spans {
+		plain(text[last:m[0]])
+		b.WriteString(utils.Highlight(text[m[0]:m[1]]))
+		last = m[1]
+	}
+	plain(text[last:])
+	return b.String()
+}
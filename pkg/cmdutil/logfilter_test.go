@@ -0,0 +1,128 @@
+package cmdutil
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func mustLogFilter(t *testing.T, grep, exclude []string) *LogFilter {
+	f, err := NewLogFilter(grep, exclude)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return f
+}
+
+func TestNewLogFilter(t *testing.T) {
+	if f, err := NewLogFilter(nil, nil); f != nil || err != nil {
+		t.Errorf("NewLogFilter(nil, nil) = %v, %v, want nil filter", f, err)
+	}
+	_, err := NewLogFilter([]string{"ok"}, []string{"("})
+	var flagErr *FlagError
+	if !errors.As(err, &flagErr) {
+		t.Errorf("error = %v, want FlagError", err)
+	}
+}
+
+func TestLogFilter_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		grep    []string
+		exclude []string
+		text    string
+		want    bool
+	}{
+		{name: "no include keeps all", exclude: []string{"debug"}, text: "epoch 1", want: true},
+		{name: "excluded", exclude: []string{"debug"}, text: "debug: epoch 1", want: false},
+		{name: "included", grep: []string{"loss"}, text: "loss=0.1", want: true},
+		{name: "not included", grep: []string{"loss"}, text: "epoch 1", want: false},
+		{name: "any include", grep: []string{"loss", "acc"}, text: "acc=0.9", want: true},
+		{name: "exclude wins", grep: []string{"loss"}, exclude: []string{"nan"}, text: "loss=nan", want: false},
+		{name: "anchored", grep: []string{"^ERROR"}, text: "no ERROR", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustLogFilter(t, tt.grep, tt.exclude)
+			if got := f.Match(tt.text); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFilter_matches(t *testing.T) {
+	tests := []struct {
+		name string
+		grep []string
+		text string
+		want [][]int
+	}{
+		{name: "none", grep: []string{"loss"}, text: "epoch 1", want: nil},
+		{name: "sorted", grep: []string{"b", "a"}, text: "a b a", want: [][]int{{0, 1}, {2, 3}, {4, 5}}},
+		{name: "overlapping", grep: []string{"abc", "bcd"}, text: "xabcdx", want: [][]int{{1, 5}}},
+		{name: "adjacent", grep: []string{"ab", "cd"}, text: "abcd", want: [][]int{{0, 4}}},
+		{name: "contained", grep: []string{"abcd", "bc"}, text: "abcd", want: [][]int{{0, 4}}},
+		// the merged spans reuse the backing array of the found ones, a
+		// later span must not overwrite an earlier one
+		{name: "merge then keep", grep: []string{"ab", "bc", "e"}, text: "abc e", want: [][]int{{0, 3}, {4, 5}}},
+		{name: "empty matches", grep: []string{"x*", "b"}, text: "abxc", want: [][]int{{1, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustLogFilter(t, tt.grep, nil)
+			if got := f.matches(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matches(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_logLevel(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "2020/10/01 ERROR: out of memory", want: "error"},
+		{text: `time=12:00 level=fatal msg="exit"`, want: "error"},
+		{text: "CRITICAL disk full", want: "error"},
+		{text: "WARNING: deprecated", want: "warn"},
+		{text: "level=warn msg=slow", want: "warn"},
+		{text: "WARN and ERROR", want: "error"},
+		{text: "no errors, 0 warnings", want: ""},
+		{text: "TERRORS", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := logLevel(tt.text); got != tt.want {
+				t.Errorf("logLevel(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if got := levelColorFunc(tt.text); (got == nil) != (tt.want == "") {
+				t.Errorf("levelColorFunc(%q) is nil = %v, want %v", tt.text, got == nil, tt.want == "")
+			}
+		})
+	}
+}
+
+func Test_highlight_noColor(t *testing.T) {
+	old, ok := os.LookupEnv("NO_COLOR")
+	os.Setenv("NO_COLOR", "1")
+	defer func() {
+		if ok {
+			os.Setenv("NO_COLOR", old)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	f := mustLogFilter(t, []string{"loss", "oss="}, nil)
+	for _, text := range []string{"ERROR loss=nan", "WARNING: slow", "loss=0.1 loss=0.2", "epoch 1"} {
+		if got := highlight(text, f); got != text {
+			t.Errorf("highlight(%q) = %q, want the text unchanged", text, got)
+		}
+	}
+	if got := highlight("ERROR", nil); got != "ERROR" {
+		t.Errorf("highlight without filter = %q, want %q", got, "ERROR")
+	}
+}
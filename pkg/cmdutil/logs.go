@@ -0,0 +1,120 @@
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/kaecloud/kaectl/api"
+	"github.com/kaecloud/kaectl/pkg/iostreams"
+	"github.com/kaecloud/kaectl/utils"
+)
+
+// LogPrinter writes the log streams returned by JobClient.Logs
+type LogPrinter struct {
+	IO *iostreams.IOStreams
+	// Timestamps prepends the time to every line
+	Timestamps bool
+	// PodPrefix prepends the colored name of the pod to every line
+	PodPrefix bool
+	// Filter selects the lines to print if it is not nil, the saved lines
+	// are not filtered
+	Filter *LogFilter
+	// Highlight colors the lines which look like errors or warnings and
+	// marks the matches of Filter
+	Highlight bool
+	// Saver also saves the lines to files if it is not nil
+	Saver *LogSaver
+}
+
+// podColors are the colors of the pod prefixes, a pod gets the same color
+// in every run
+var podColors = []func(string) string{
+	utils.Cyan,
+	utils.Magenta,
+	utils.Yellow,
+	utils.Green,
+	utils.Blue,
+	utils.Red,
+}
+
+func podColor(pod string) func(string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(pod))
+	return podColors[h.Sum32()%uint32(len(podColors))]
+}
+
+// Print writes the lines of a log stream to IO.Out until the stream ends, it
+// returns the error ending the stream. Reconnecting is noticed on IO.ErrOut.
+// Saver is closed when the stream ends.
+func (p *LogPrinter) Print(ctx context.Context, events <-chan api.LogEvent) (err error) {
+	if p.Saver != nil {
+		defer func() {
+			if closeErr := p.Saver.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+	// the compressed lines are flushed periodically, so the saved log is
+	// complete up to a few seconds ago even if kaectl is killed
+	var flush <-chan time.Time
+	if p.Saver != nil && p.Saver.Gzip {
+		ticker := time.NewTicker(LogFlushInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+	for {
+		var ev api.LogEvent
+		var ok bool
+		select {
+		case ev, ok = <-events:
+			if !ok {
+				return ctx.Err()
+			}
+		case <-flush:
+			if err := p.Saver.Flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case ev.Err != nil:
+			return ev.Err
+		case ev.Reconnected:
+			fmt.Fprintln(p.IO.ErrOut, utils.Gray("-- lost connection to the server, reconnected --"))
+		default:
+			if err := p.printLine(ev.Line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *LogPrinter) printLine(line api.LogLine) error {
+	var ts string
+	if p.Timestamps && !line.Time.IsZero() {
+		ts = line.Time.Format(time.RFC3339Nano) + " "
+	}
+	if p.Filter == nil || p.Filter.Match(line.Text) {
+		text := line.Text
+		if p.Highlight {
+			text = highlight(text, p.Filter)
+		}
+		if p.PodPrefix {
+			fmt.Fprintf(p.IO.Out, "%s %s%s\n", podColor(line.Pod)("["+line.Pod+"]"), ts, text)
+		} else {
+			fmt.Fprintf(p.IO.Out, "%s%s\n", ts, text)
+		}
+	}
+
+	if p.Saver == nil {
+		return nil
+	}
+	text := ts + line.Text
+	// the file of a pod doesn't need the prefix
+	if p.PodPrefix && p.Saver.Dir == "" {
+		text = "[" + line.Pod + "] " + text
+	}
+	return p.Saver.Write(line.Pod, text)
+}
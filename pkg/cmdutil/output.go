@@ -0,0 +1,54 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/ghodss/yaml"
+)
+
+// OutputFormat is the machine-readable format selected by --json or --yaml
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = ""
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+// NewOutputFormat converts the values of --json and --yaml flags to an OutputFormat
+func NewOutputFormat(asJSON, asYAML bool) (OutputFormat, error) {
+	switch {
+	case asJSON && asYAML:
+		return OutputTable, &FlagError{Err: errors.New("specify only one of `--json` or `--yaml`")}
+	case asJSON:
+		return OutputJSON, nil
+	case asYAML:
+		return OutputYAML, nil
+	default:
+		return OutputTable, nil
+	}
+}
+
+// WriteStructured writes v to w in the given machine-readable format
+func WriteStructured(w io.Writer, format OutputFormat, v interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case OutputYAML:
+		data, err = yaml.Marshal(v)
+	default:
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -1,6 +1,7 @@
 package cmdutil
 
 import (
+	"context"
 	"fmt"
 	"github.com/kaecloud/kaectl/api"
 	"github.com/kaecloud/kaectl/pkg/spec"
@@ -9,9 +10,10 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 )
 
-func PrepareJob(sp *spec.JobSpec, c *api.JobClient) (err error) {
+func PrepareJob(ctx context.Context, sp *spec.JobSpec, c *api.JobClient) (err error) {
 	if sp.Prepare == nil || len(sp.Prepare.Artifacts) == 0 {
 		return nil
 	}
@@ -29,7 +31,7 @@ func PrepareJob(sp *spec.JobSpec, c *api.JobClient) (err error) {
 		if err != nil {
 			return err
 		}
-		objUrl, err = c.UploadArtifact(sp.Name, zipFileName, zipFileName)
+		objUrl, err = c.UploadArtifact(ctx, sp.Name, zipFileName, zipFileName)
 		os.Remove(zipFileName)
 
 		if err != nil {
@@ -40,3 +42,49 @@ func PrepareJob(sp *spec.JobSpec, c *api.JobClient) (err error) {
 	}
 	return nil
 }
+
+// JobPollInterval is the time between two requests of the job status when
+// waiting for a job
+var JobPollInterval = 3 * time.Second
+
+// WaitJobPhase polls the status of job until it is in phase or it can't reach
+// phase anymore as it finished, onPhase is called with every phase polled
+// and may be nil
+func WaitJobPhase(ctx context.Context, c *api.JobClient, name, cluster, phase string, onPhase func(string)) (*api.JobStatus, error) {
+	for {
+		status, err := c.Status(ctx, name, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if onPhase != nil {
+			onPhase(status.Phase)
+		}
+		switch status.Phase {
+		case phase, api.PhaseSucceeded, api.PhaseFailed:
+			// a finished job doesn't change anymore
+			return status, nil
+		}
+
+		timer := time.NewTimer(JobPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// JobStatusColorFunc returns the color used to display a job in the given status
+func JobStatusColorFunc(status string) func(string) string {
+	switch strings.ToLower(status) {
+	case "succeeded", "complete", "completed":
+		return utils.Green
+	case "failed", "error":
+		return utils.Red
+	case "pending", "running":
+		return utils.Yellow
+	default:
+		return nil
+	}
+}
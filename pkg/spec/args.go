@@ -12,3 +12,15 @@ type CreateJobArgs struct {
 	Cluster     string `json:"cluster"`
 }
 
+type CreateAppArgs struct {
+	Name    string `json:"name"`
+	Git     string `json:"git,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Spec    string `json:"spec,omitempty"`
+}
+
+type DeployAppArgs struct {
+	Tag     string `json:"tag,omitempty"`
+	Spec    string `json:"spec,omitempty"`
+	Cluster string `json:"cluster"`
+}
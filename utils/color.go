@@ -18,6 +18,8 @@ var (
 	Green   = makeColorFunc("green")
 	Gray    = makeColorFunc("black+h")
 	Bold    = makeColorFunc("default+b")
+	// Highlight marks a match like grep does
+	Highlight = makeColorFunc("black:yellow")
 )
 
 // NewColorable returns an output stream that handles ANSI color sequences on Windows